@@ -0,0 +1,130 @@
+package identypo
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// Analyzer reports typos in identifiers. It can be run with singlechecker, multichecker or go vet -vettool,
+// and accepts the same flags as the identypo command (apart from -set_exit_status, which is left to the driver).
+var Analyzer = &analysis.Analyzer{
+	Name: "identypo",
+	Doc:  "find typos in identifiers (functions, function calls, methods, variables, constants, type declarations, fields, packages, labels, etc.)",
+	Run:  runAnalyzer,
+}
+
+// analyzerFlags holds the configuration bound to Analyzer.Flags. analyzerDictionaries, analyzerWordLists,
+// analyzerAcronyms and analyzerKinds are the comma separated lists given with -dict, -words, -acronyms and -kinds,
+// which -functions, -constants and -variables add to.
+var (
+	analyzerFlags                                                            Flags
+	analyzerDictionaries, analyzerWordLists, analyzerAcronyms, analyzerKinds string
+	analyzerFunctions, analyzerConstants, analyzerVariables                  bool
+)
+
+func init() {
+	Analyzer.Flags.StringVar(&analyzerFlags.Ignores, "i", "", "ignore the following words requiring correction, comma separated (e.g. -i=\"nto,creater\")")
+	Analyzer.Flags.StringVar(&analyzerDictionaries, "dict", "", "comma separated list of dictionary files with additional corrections, one \"wrong,right\" or \"wrong -> right\" pair per line")
+	Analyzer.Flags.StringVar(&analyzerWordLists, "words", "", "comma separated list of word list files (e.g. -words=/usr/share/dict/words), words that aren't in them but are close to a word that is are reported")
+	Analyzer.Flags.IntVar(&analyzerFlags.EditDistance, "edit_distance", DefaultEditDistance, "largest number of edits (1 or 2) a word can be from a word in the -words lists to be reported")
+	Analyzer.Flags.StringVar(&analyzerAcronyms, "acronyms", "", "comma separated list of acronyms to recognize when splitting identifiers into words, in addition to golint's common initialisms (e.g. -acronyms=gRPC,IPv6)")
+	Analyzer.Flags.BoolVar(&analyzerFlags.Segment, "segment", false, "also find typos in words that run together in lower case, such as the \"recieved\" of recieveddata")
+	Analyzer.Flags.Float64Var(&analyzerFlags.SegmentConfidence, "segment_confidence", DefaultSegmentConfidence, "confidence (between 0 and 1) a split of run together words needs for its typos to be reported with -segment")
+	Analyzer.Flags.BoolVar(&analyzerFlags.Consistency, "consistency", false, "also report words spelled more than one way across the identifiers of each package, suggesting the spelling used most often")
+	Analyzer.Flags.StringVar(&analyzerFlags.Locale, "locale", "", "enforce US or UK spellings (e.g. -locale=US reports \"Colour\"), by default either is accepted")
+	Analyzer.Flags.BoolVar(&analyzerFlags.IncludeTests, "tests", true, "include test (*_test.go) files")
+	Analyzer.Flags.StringVar(&analyzerKinds, "kinds", "", "comma separated list of kinds of identifiers to check, all by default (e.g. -kinds=type,field)")
+	Analyzer.Flags.BoolVar(&analyzerFunctions, "functions", false, "find typos in functions only, the same as -kinds=func")
+	Analyzer.Flags.BoolVar(&analyzerConstants, "constants", false, "find typos in constants only, the same as -kinds=const")
+	Analyzer.Flags.BoolVar(&analyzerVariables, "variables", false, "find typos in variables only, the same as -kinds=var")
+	Analyzer.Flags.BoolVar(&analyzerFlags.DeclarationsOnly, "declarations", false, "find typos only in identifiers declared in the analyzed package, ignoring uses")
+	Analyzer.Flags.BoolVar(&analyzerFlags.ExportedOnly, "exported", false, "find typos only in the public API: exported package-level declarations, methods and struct fields")
+	Analyzer.Flags.BoolVar(&analyzerFlags.GroupByDeclaration, "group", true, "report each misspelled declaration once, rather than once per use")
+	Analyzer.Flags.BoolVar(&analyzerFlags.ReportUnusedSuppressions, "unused_suppressions", false, "report //identypo:ignore and //nolint:identypo comments that did not suppress anything")
+}
+
+func runAnalyzer(pass *analysis.Pass) (interface{}, error) {
+	flags := analyzerFlags
+	if analyzerDictionaries != "" {
+		flags.Dictionaries = strings.Split(analyzerDictionaries, ",")
+	}
+	if analyzerWordLists != "" {
+		flags.WordLists = strings.Split(analyzerWordLists, ",")
+	}
+	if analyzerAcronyms != "" {
+		flags.Acronyms = strings.Split(analyzerAcronyms, ",")
+	}
+	if analyzerKinds != "" {
+		flags.Kinds = strings.Split(analyzerKinds, ",")
+	}
+	if analyzerFunctions {
+		flags.Kinds = append(flags.Kinds, KindFunc)
+	}
+	if analyzerConstants {
+		flags.Kinds = append(flags.Kinds, KindConst)
+	}
+	if analyzerVariables {
+		flags.Kinds = append(flags.Kinds, KindVar)
+	}
+	flags, err := flags.prepare()
+	if err != nil {
+		return nil, err
+	}
+
+	c := newChecker(flags)
+	c.info = pass.TypesInfo
+
+	var files []*ast.File
+	for _, f := range pass.Files {
+		name := pass.Fset.File(f.Pos()).Name()
+		if synthesized(name) {
+			continue
+		}
+
+		if !analyzerFlags.IncludeTests && strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+
+		files = append(files, f)
+	}
+
+	findings, idents := c.checkFiles(pass.Fset, files)
+
+	for _, group := range c.group(pass.Fset, findings, idents) {
+		finding, ident := findings[group[0]], idents[group[0]]
+
+		message := finding.message()
+		if finding.Severity == SeverityPublic {
+			message += " " + publicMarker
+		}
+
+		// report the range of the misspelled word, rather than the whole identifier
+		diagnostic := analysis.Diagnostic{
+			Pos:      ident.Pos() + token.Pos(finding.Offset),
+			End:      ident.Pos() + token.Pos(finding.Offset+finding.Length),
+			Category: finding.Severity,
+			Message:  message,
+		}
+		for _, j := range group[1:] {
+			diagnostic.Related = append(diagnostic.Related, analysis.RelatedInformation{
+				Pos:     idents[j].Pos(),
+				End:     idents[j].End(),
+				Message: fmt.Sprintf("%v referenced here", finding.Identifier),
+			})
+		}
+
+		pass.Report(diagnostic)
+	}
+
+	if analyzerFlags.ReportUnusedSuppressions {
+		for _, s := range c.unusedSuppressions() {
+			pass.Reportf(s.pos, "unused suppression %v", s.text)
+		}
+	}
+
+	return nil, nil
+}
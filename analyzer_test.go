@@ -0,0 +1,20 @@
+package identypo
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), Analyzer, "a")
+}
+
+func TestAnalyzerWithoutTests(t *testing.T) {
+	if err := Analyzer.Flags.Set("tests", "false"); err != nil {
+		t.Fatal(err)
+	}
+	defer Analyzer.Flags.Set("tests", "true")
+
+	analysistest.Run(t, analysistest.TestData(), Analyzer, "b")
+}
@@ -0,0 +1,140 @@
+package identypo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// BaselineEntry is a finding recorded in a baseline. Entries are keyed by file, identifier and misspelled word
+// rather than by position, so they still match after unrelated edits move the finding to another line.
+type BaselineEntry struct {
+	Filename   string `json:"file"`
+	Identifier string `json:"identifier"`
+	Word       string `json:"word"`
+}
+
+// String formats the entry the way stale entries are reported by the identypo command.
+func (e BaselineEntry) String() string {
+	return fmt.Sprintf("%v: %q in %v", e.Filename, e.Word, e.Identifier)
+}
+
+// Baseline is a set of known findings, used to report only findings that are new since it was written.
+type Baseline struct {
+	Entries []BaselineEntry
+	// Dir is the directory entry file names are relative to (the directory of the baseline file), so a baseline
+	// matches whichever directory identypo is run from.
+	Dir string
+}
+
+// NewBaseline returns a baseline recording findings, with file names relative to dir. Findings sharing a file,
+// identifier and word are recorded once.
+func NewBaseline(dir string, findings []Finding) *Baseline {
+	seen := make(map[BaselineEntry]bool)
+	b := &Baseline{Entries: []BaselineEntry{}, Dir: dir}
+	for _, f := range findings {
+		e := b.key(f)
+		if seen[e] {
+			continue
+		}
+		seen[e] = true
+		b.Entries = append(b.Entries, e)
+	}
+
+	// sort so the baseline file doesn't change unless the findings do
+	sort.Slice(b.Entries, func(i, j int) bool {
+		ei, ej := b.Entries[i], b.Entries[j]
+		if ei.Filename != ej.Filename {
+			return ei.Filename < ej.Filename
+		}
+		if ei.Identifier != ej.Identifier {
+			return ei.Identifier < ej.Identifier
+		}
+		return ei.Word < ej.Word
+	})
+
+	return b
+}
+
+// LoadBaseline reads a baseline file written by WriteBaseline.
+func LoadBaseline(filename string) (*Baseline, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	b := Baseline{Dir: filepath.Dir(filename)}
+	if err := json.NewDecoder(f).Decode(&b.Entries); err != nil {
+		return nil, fmt.Errorf("%v: %v", filename, err)
+	}
+
+	return &b, nil
+}
+
+// WriteBaseline writes a baseline of findings to w as a JSON array, with one object per entry. File names are
+// written relative to dir, which should be the directory of the baseline file.
+func WriteBaseline(w io.Writer, dir string, findings []Finding) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(NewBaseline(dir, findings).Entries)
+}
+
+// Filter returns the findings that are not in the baseline, along with the baseline entries for checked files
+// that no longer match any finding (those typos have been fixed, so the entries can be deleted). Entries for
+// files that weren't checked are never stale, since the baseline may cover more than one run's arguments.
+func (b *Baseline) Filter(findings []Finding, checked []string) ([]Finding, []BaselineEntry) {
+	known := make(map[BaselineEntry]bool, len(b.Entries))
+	for _, e := range b.Entries {
+		known[e] = false
+	}
+
+	var fresh []Finding
+	for _, f := range findings {
+		e := b.key(f)
+		if _, ok := known[e]; ok {
+			known[e] = true
+			continue
+		}
+		fresh = append(fresh, f)
+	}
+
+	files := make(map[string]bool, len(checked))
+	for _, filename := range checked {
+		files[b.filename(filename)] = true
+	}
+
+	var stale []BaselineEntry
+	for _, e := range b.Entries {
+		if !known[e] && files[e.Filename] {
+			stale = append(stale, e)
+		}
+	}
+
+	return fresh, stale
+}
+
+// key returns the baseline entry matching f.
+func (b *Baseline) key(f Finding) BaselineEntry {
+	return BaselineEntry{
+		Filename:   b.filename(f.Filename),
+		Identifier: f.Identifier,
+		Word:       f.Word,
+	}
+}
+
+// filename returns filename (relative to the current directory) as recorded in the baseline: relative to b.Dir,
+// with forward slashes so a baseline can be shared between operating systems.
+func (b *Baseline) filename(filename string) string {
+	if abs, err := filepath.Abs(filename); err == nil {
+		if dir, err := filepath.Abs(b.Dir); err == nil {
+			if rel, err := filepath.Rel(dir, abs); err == nil {
+				filename = rel
+			}
+		}
+	}
+	return filepath.ToSlash(filename)
+}
@@ -0,0 +1,118 @@
+package identypo
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func Test_BaselineFilter(t *testing.T) {
+	baselined := []Finding{
+		{Filename: "a.go", Line: 3, Word: "Succesful", Identifier: "isSuccesful"},
+		{Filename: "a.go", Line: 8, Word: "Succesful", Identifier: "isSuccesful"},
+		{Filename: "b.go", Line: 5, Word: "recieve", Identifier: "recieve"},
+	}
+	b := NewBaseline("", baselined)
+
+	wantEntries := []BaselineEntry{
+		{Filename: "a.go", Identifier: "isSuccesful", Word: "Succesful"},
+		{Filename: "b.go", Identifier: "recieve", Word: "recieve"},
+	}
+	if !reflect.DeepEqual(b.Entries, wantEntries) {
+		t.Fatalf("\ngot %v\nexp %v\n", b.Entries, wantEntries)
+	}
+
+	tests := []struct {
+		name      string
+		findings  []Finding
+		checked   []string
+		wantFresh []Finding
+		wantStale []BaselineEntry
+	}{
+		{name: "unchanged",
+			findings: baselined,
+			checked:  []string{"a.go", "b.go"},
+		},
+		{name: "moved to another line",
+			findings: []Finding{
+				{Filename: "a.go", Line: 30, Word: "Succesful", Identifier: "isSuccesful"},
+				{Filename: "b.go", Line: 1, Word: "recieve", Identifier: "recieve"},
+			},
+			checked: []string{"a.go", "b.go"},
+		},
+		{name: "new findings",
+			findings: []Finding{
+				{Filename: "a.go", Line: 3, Word: "Succesful", Identifier: "isSuccesful"},
+				{Filename: "a.go", Line: 4, Word: "Succesful", Identifier: "wasSuccesful"},
+				{Filename: "b.go", Line: 5, Word: "recieve", Identifier: "recieve"},
+				{Filename: "c.go", Line: 5, Word: "recieve", Identifier: "recieve"},
+			},
+			checked: []string{"a.go", "b.go", "c.go"},
+			wantFresh: []Finding{
+				{Filename: "a.go", Line: 4, Word: "Succesful", Identifier: "wasSuccesful"},
+				{Filename: "c.go", Line: 5, Word: "recieve", Identifier: "recieve"},
+			},
+		},
+		{name: "stale entries",
+			findings: []Finding{
+				{Filename: "b.go", Line: 5, Word: "recieve", Identifier: "recieve"},
+			},
+			checked: []string{"a.go", "b.go"},
+			wantStale: []BaselineEntry{
+				{Filename: "a.go", Identifier: "isSuccesful", Word: "Succesful"},
+			},
+		},
+		{name: "files not checked",
+			findings: []Finding{
+				{Filename: "b.go", Line: 5, Word: "recieve", Identifier: "recieve"},
+			},
+			checked: []string{"b.go"},
+		},
+		{name: "relative to the baseline directory",
+			findings: []Finding{
+				{Filename: "sub/../a.go", Line: 3, Word: "Succesful", Identifier: "isSuccesful"},
+				{Filename: "./b.go", Line: 5, Word: "recieve", Identifier: "recieve"},
+			},
+			checked: []string{"sub/../a.go", "./b.go"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fresh, stale := b.Filter(tt.findings, tt.checked)
+			if !reflect.DeepEqual(fresh, tt.wantFresh) {
+				t.Fatalf("new findings\ngot %v\nexp %v\n", fresh, tt.wantFresh)
+			}
+			if !reflect.DeepEqual(stale, tt.wantStale) {
+				t.Fatalf("stale entries\ngot %v\nexp %v\n", stale, tt.wantStale)
+			}
+		})
+	}
+}
+
+func Test_WriteBaseline(t *testing.T) {
+	findings, checked, err := FindIdentifierTyposAndFiles([]string{"testdata/file.go"}, Flags{})
+	if err != nil {
+		t.Fatalf("FindIdentifierTyposAndFiles %v", err)
+	}
+
+	// the baseline is written outside the working directory, so its file names are relative to another directory
+	dir, write := fixture(t, nil)
+	var buf bytes.Buffer
+	if err := WriteBaseline(&buf, dir, findings); err != nil {
+		t.Fatalf("WriteBaseline %v", err)
+	}
+	write("baseline.json", buf.String())
+
+	b, err := LoadBaseline(filepath.Join(dir, "baseline.json"))
+	if err != nil {
+		t.Fatalf("LoadBaseline %v", err)
+	}
+
+	if fresh, stale := b.Filter(findings, checked); len(fresh) != 0 || len(stale) != 0 {
+		t.Fatalf("expected baseline to match every finding\nnew %v\nstale %v\n", fresh, stale)
+	}
+	if len(b.Entries) != 5 {
+		t.Fatalf("expected an entry per misspelled identifier, got %v", b.Entries)
+	}
+}
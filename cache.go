@@ -0,0 +1,227 @@
+package identypo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// verdict is the result of checking a single word against the dictionary. Words found with Flags.WordLists may also
+// have alternatives, other known words as close to the word as its correction.
+type verdict struct {
+	correction   string
+	alternatives []string
+	misspelled   bool
+}
+
+// suggestionSeparator separates a correction from its alternatives in the cache, since neither contains it.
+const suggestionSeparator = "|"
+
+// suggestions returns the correction and alternatives of v, as recorded in the cache.
+func (v verdict) suggestions() string {
+	return strings.Join(append([]string{v.correction}, v.alternatives...), suggestionSeparator)
+}
+
+// cachedVerdict returns the verdict for a word recorded in the cache with suggestions, if it was misspelled.
+func cachedVerdict(suggestions string, misspelled bool) verdict {
+	if !misspelled {
+		return verdict{}
+	}
+	return misspelledVerdict(strings.Split(suggestions, suggestionSeparator))
+}
+
+// misspelledVerdict returns the verdict for a misspelled word with the suggested corrections, nearest first.
+func misspelledVerdict(suggestions []string) verdict {
+	v := verdict{correction: suggestions[0], misspelled: true}
+	if len(suggestions) > 1 {
+		v.alternatives = suggestions[1:]
+	}
+	return v
+}
+
+// wordCache memoizes the verdict for each distinct word checked during a run. It's safe for concurrent use.
+type wordCache struct {
+	mu    sync.RWMutex
+	words map[string]verdict
+}
+
+func (w *wordCache) get(word string) (verdict, bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	v, ok := w.words[word]
+	return v, ok
+}
+
+func (w *wordCache) put(word string, v verdict) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.words == nil {
+		w.words = make(map[string]verdict)
+	}
+	w.words[word] = v
+}
+
+// fileCache is a cache of the misspelled words in each file, persisted between runs in Flags.Cache. Files are
+// keyed by the hash of their content, and the whole cache is discarded if the dictionary it was built with changes.
+// Since every word of a cached file was checked when it was recorded, any word missing from its entry is spelled
+// correctly, so cached files are checked without consulting the dictionary at all. It's safe for concurrent use.
+type fileCache struct {
+	filename string
+
+	mu         sync.Mutex
+	dictionary string
+	// loaded holds the entries read from filename, while seen holds the entries of the files checked this run.
+	// loadedNames and seenNames map the absolute name of each of those files to the hash of its content.
+	loaded      map[string]map[string]string
+	loadedNames map[string]string
+	seen        map[string]map[string]string
+	seenNames   map[string]string
+	byName      map[string]map[string]string
+}
+
+// cacheFile is the format of a persisted fileCache.
+type cacheFile struct {
+	Dictionary string                       `json:"dictionary"`
+	Files      map[string]map[string]string `json:"files"`
+	Names      map[string]string            `json:"names"`
+}
+
+// loadCache reads the cache persisted in filename. A missing or unreadable cache is treated as empty,
+// since it will be rewritten at the end of the run.
+func loadCache(filename string) *fileCache {
+	fc := &fileCache{
+		filename:  filename,
+		seen:      make(map[string]map[string]string),
+		seenNames: make(map[string]string),
+		byName:    make(map[string]map[string]string),
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return fc
+	}
+
+	var cf cacheFile
+	if err := json.Unmarshal(data, &cf); err != nil {
+		return fc
+	}
+	fc.dictionary, fc.loaded, fc.loadedNames = cf.Dictionary, cf.Files, cf.Names
+
+	return fc
+}
+
+// useDictionary discards the loaded entries if they were recorded with a different dictionary.
+func (fc *fileCache) useDictionary(hash string) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	if fc.dictionary != hash {
+		fc.loaded = nil
+	}
+	fc.dictionary = hash
+}
+
+// file starts tracking filename with content src. If it was cached by a previous run, its misspelled words
+// (keyed by word, with their corrections) are returned, and must not be modified.
+func (fc *fileCache) file(filename string, src []byte) (map[string]string, bool) {
+	hash := contentHash(src)
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		abs = filename
+	}
+
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	words, cached := fc.loaded[hash]
+	if !cached {
+		words = fc.seen[hash]
+	}
+	if words == nil {
+		words = make(map[string]string)
+	}
+	fc.seen[hash] = words
+	fc.seenNames[abs] = hash
+	fc.byName[filename] = words
+
+	return words, cached
+}
+
+// record notes that word is misspelled in filename, which was not cached by a previous run, with the suggestions
+// of its verdict.
+func (fc *fileCache) record(filename, word, suggestions string) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	if words, ok := fc.byName[filename]; ok {
+		words[word] = suggestions
+	}
+}
+
+// save persists the entries of the files seen this run, along with the loaded entries of files that weren't checked
+// this run but still exist unchanged (so runs over different packages can share a cache). Entries of files that no
+// longer exist (or changed) are dropped.
+func (fc *fileCache) save() error {
+	if fc == nil {
+		return nil
+	}
+
+	fc.mu.Lock()
+	cf := cacheFile{
+		Dictionary: fc.dictionary,
+		Files:      make(map[string]map[string]string, len(fc.seen)),
+		Names:      make(map[string]string, len(fc.seenNames)),
+	}
+	for hash, words := range fc.seen {
+		cf.Files[hash] = words
+	}
+	for name, hash := range fc.seenNames {
+		cf.Names[name] = hash
+	}
+	for name, hash := range fc.loadedNames {
+		words, ok := fc.loaded[hash]
+		if _, seen := fc.seenNames[name]; seen || !ok {
+			continue
+		}
+		if src, err := os.ReadFile(name); err != nil || contentHash(src) != hash {
+			continue
+		}
+		cf.Files[hash] = words
+		cf.Names[name] = hash
+	}
+	data, err := json.Marshal(cf)
+	fc.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	// write to a temporary file first, so an interrupted run can't leave a truncated cache behind
+	tmp := fc.filename + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, fc.filename)
+}
+
+// contentHash returns the hash of a file's content src, which its entry in the cache is keyed by.
+func contentHash(src []byte) string {
+	sum := sha256.Sum256(src)
+	return hex.EncodeToString(sum[:])
+}
+
+// cacheVersion is included in the dictionary hash, so it can be bumped to discard caches whenever the way
+// words are checked changes.
+const cacheVersion = "identypo-cache-2"
+
+// dictionaryHash returns a hash of the rules used by replacements and the settings that decide which words of an
+// identifier are checked (such as the acronyms identifiers are split on), identifying the verdicts they produce.
+func dictionaryHash(replacements []string, settings ...[]string) string {
+	parts := []string{cacheVersion, strings.Join(replacements, "\n")}
+	for _, s := range settings {
+		parts = append(parts, strings.Join(s, "\n"))
+	}
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\n\n")))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,286 @@
+package identypo
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func Test_FindIdentifierTyposCache(t *testing.T) {
+	dir, write := fixture(t, map[string]string{
+		"go.mod": "module example.com/fixture\n",
+		"a.go":   "package fixture\n\nvar begining, tennant int\n",
+		"b.go":   "package fixture\n\nfunc recieve() {}\n",
+	})
+
+	cache := filepath.Join(dir, "identypo.cache")
+	defer inModule(t, dir)()
+
+	tests := []struct {
+		name   string
+		update func()
+		flags  Flags
+		want   []string
+	}{
+		{name: "cold cache",
+			want: []string{
+				"a.go:3:5 \"begining\" should be beginning in begining",
+				"b.go:3:6 \"recieve\" should be receive in recieve",
+			},
+		},
+		{name: "warm cache",
+			want: []string{
+				"a.go:3:5 \"begining\" should be beginning in begining",
+				"b.go:3:6 \"recieve\" should be receive in recieve",
+			},
+		},
+		{name: "changed file",
+			update: func() { write("b.go", "package fixture\n\nfunc recieve(succesful bool) {}\n") },
+			want: []string{
+				"a.go:3:5 \"begining\" should be beginning in begining",
+				"b.go:3:6 \"recieve\" should be receive in recieve",
+				"b.go:3:14 \"succesful\" should be successful in succesful",
+			},
+		},
+		{name: "changed dictionary",
+			flags: Flags{Corrections: map[string]string{"tennant": "tenant"}},
+			want: []string{
+				"a.go:3:5 \"begining\" should be beginning in begining",
+				"a.go:3:15 \"tennant\" should be tenant in tennant",
+				"b.go:3:6 \"recieve\" should be receive in recieve",
+				"b.go:3:14 \"succesful\" should be successful in succesful",
+			},
+		},
+		{name: "declarations only",
+			update: func() {
+				write("dep/dep.go", "package dep\n\nfunc Recieve() {}\n")
+				write("c.go", "package fixture\n\nimport \"example.com/fixture/dep\"\n\nfunc f() { dep.Recieve() }\n")
+			},
+			flags: Flags{Corrections: map[string]string{"tennant": "tenant"}, DeclarationsOnly: true},
+			want: []string{
+				"a.go:3:5 \"begining\" should be beginning in begining",
+				"a.go:3:15 \"tennant\" should be tenant in tennant",
+				"b.go:3:6 \"recieve\" should be receive in recieve",
+				"b.go:3:14 \"succesful\" should be successful in succesful",
+			},
+		},
+		{name: "uses cached with declarations only",
+			flags: Flags{Corrections: map[string]string{"tennant": "tenant"}},
+			want: []string{
+				"a.go:3:5 \"begining\" should be beginning in begining",
+				"a.go:3:15 \"tennant\" should be tenant in tennant",
+				"b.go:3:6 \"recieve\" should be receive in recieve",
+				"b.go:3:14 \"succesful\" should be successful in succesful",
+				"c.go:5:16 \"Recieve\" should be Receive in Recieve [public API]",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.update != nil {
+				tt.update()
+			}
+
+			flags := tt.flags
+			flags.Cache = cache
+			findings, err := FindIdentifierTypos([]string{"."}, flags)
+			if err != nil {
+				t.Fatalf("FindIdentifierTypos %v", err)
+			}
+
+			var got []string
+			for _, f := range findings {
+				got = append(got, f.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("\ngot %q\nexp %q\n", got, tt.want)
+			}
+
+			if _, err := os.Stat(cache); err != nil {
+				t.Fatalf("expected cache to be written, %v", err)
+			}
+		})
+	}
+}
+
+func Test_fileCacheSave(t *testing.T) {
+	dir, write := fixture(t, map[string]string{
+		"go.mod": "module example.com/fixture\n",
+		"a/a.go": "package a\n\nvar begining int\n",
+		"b/b.go": "package b\n\nfunc recieve() {}\n",
+		"c/c.go": "package c\n\nvar tennant int\n",
+	})
+
+	cache := filepath.Join(dir, "identypo.cache")
+	defer inModule(t, dir)()
+
+	tests := []struct {
+		name   string
+		update func()
+		args   []string
+		want   []string
+	}{
+		{name: "first package",
+			args: []string{"./a", "./c"},
+			want: []string{"a/a.go", "c/c.go"},
+		},
+		{name: "entries of other packages kept",
+			args: []string{"./b"},
+			want: []string{"a/a.go", "b/b.go", "c/c.go"},
+		},
+		{name: "changed and removed files dropped",
+			update: func() {
+				write("a/a.go", "package a\n\nvar beginning int\n")
+				if err := os.RemoveAll(filepath.Join(dir, "c")); err != nil {
+					t.Fatal(err)
+				}
+			},
+			args: []string{"./b"},
+			want: []string{"b/b.go"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.update != nil {
+				tt.update()
+			}
+
+			if _, err := FindIdentifierTypos(tt.args, Flags{Cache: cache}); err != nil {
+				t.Fatalf("FindIdentifierTypos %v", err)
+			}
+
+			fc := loadCache(cache)
+			var got []string
+			for name, hash := range fc.loadedNames {
+				if _, ok := fc.loaded[hash]; !ok {
+					t.Fatalf("no entry for %v", name)
+				}
+				rel, err := filepath.Rel(dir, name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, filepath.ToSlash(rel))
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("\ngot %q\nexp %q\n", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_checkerReplaceCachedFile(t *testing.T) {
+	fc := loadCache(filepath.Join(t.TempDir(), "missing.cache"))
+	c := newChecker(Flags{cache: fc})
+
+	// words of a file cached by a previous run come from the cache alone
+	fc.loaded = map[string]map[string]string{}
+	if _, cached := fc.file("a.go", []byte("package a")); cached {
+		t.Fatalf("expected a.go not to be cached")
+	}
+	if v := c.replace("a.go", "recieve"); !v.misspelled || v.correction != "receive" {
+		t.Fatalf("replace(recieve) = %+v", v)
+	}
+	if v := c.replace("a.go", "receive"); v.misspelled {
+		t.Fatalf("replace(receive) = %+v", v)
+	}
+
+	fc.loaded = fc.seen
+	words, cached := fc.file("b.go", []byte("package a"))
+	if !cached || !reflect.DeepEqual(words, map[string]string{"recieve": "receive"}) {
+		t.Fatalf("expected b.go to share the cached words of a.go, got %v %v", words, cached)
+	}
+}
+
+// benchmarkWords returns the words of every identifier in the generated packages.
+func benchmarkWords(b *testing.B) []string {
+	dir := generatePackages(b, 20, 10, 1)
+
+	var words []string
+	acronyms, _ := acronymList(nil)
+	fset := token.NewFileSet()
+	filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil || !strings.HasSuffix(path, ".go") {
+			return err
+		}
+		f, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			b.Fatal(err)
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			if ident, ok := n.(*ast.Ident); ok {
+				for _, w := range splitIdentifier(ident.Name, acronyms) {
+					words = append(words, w.text)
+				}
+			}
+			return true
+		})
+		return nil
+	})
+
+	return words
+}
+
+func BenchmarkReplace(b *testing.B) {
+	words := benchmarkWords(b)
+
+	b.Run("replacer", func(b *testing.B) {
+		c := newChecker(Flags{})
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			for _, word := range words {
+				c.compiled().Replace(word)
+			}
+		}
+	})
+
+	b.Run("memoized", func(b *testing.B) {
+		c := newChecker(Flags{})
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			// a new cache each iteration, as each run starts with an empty one
+			c.words = wordCache{}
+			for _, word := range words {
+				c.replace("", word)
+			}
+		}
+	})
+}
+
+func BenchmarkFindTyposCache(b *testing.B) {
+	// as in most codebases, only a few files have typos
+	dir := generatePackages(b, 50, 10, 10)
+	defer inModule(b, dir)()
+
+	fset := token.NewFileSet()
+	files, info, err := parseInput([]string{"./..."}, fset, true, Flags{}.workers())
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("no cache", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			findTypos(fset, files, info, Flags{})
+		}
+	})
+
+	b.Run("warm cache", func(b *testing.B) {
+		flags, err := Flags{Cache: filepath.Join(dir, "identypo.cache")}.prepare()
+		if err != nil {
+			b.Fatal(err)
+		}
+		findTypos(fset, files, info, flags)
+		flags.cache.loaded = flags.cache.seen
+
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			findTypos(fset, files, info, flags)
+		}
+	})
+}
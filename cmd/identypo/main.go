@@ -1,25 +1,24 @@
 package main
 
 import (
+	"errors"
 	"flag"
-	"go/build"
+	"io"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/alexkohler/identypo"
 )
 
-func init() {
-	build.Default.UseAllFiles = false
-}
-
 func usage() {
 	log.Printf("Usage of %s:\n", os.Args[0])
 	log.Printf("\nidentypo[flags] # runs on package in current directory\n")
 	log.Printf("\nidentypo [flags] [packages]\n")
 	log.Printf("Flags:\n")
 	flag.PrintDefaults()
-	log.Printf("\nNOTE: by default, identypo will check for typos in every identifier (functions, function calls, variables, constants, type declarations, packages, labels). In this case, no flag needs specified.\n")
+	log.Printf("\nNOTE: by default, identypo will check for typos in every identifier (functions, function calls, methods, variables, constants, type declarations, fields, packages, labels, etc.). In this case, no flag needs specified.\n")
 }
 
 func main() {
@@ -27,25 +26,284 @@ func main() {
 	// Remove log timestamp
 	log.SetFlags(0)
 
-	ignores := flag.String("i", "", "ignore the following words requiring correction, comma separated (e.g. -i=\"nto,creater\")")
-	includeTests := flag.Bool("tests", true, "include test (*_test.go) files")
-	functionsOnly := flag.Bool("functions", false, "find typos in function declarations only")
-	constantsOnly := flag.Bool("constants", false, "find typos in constants only")
-	variablesOnly := flag.Bool("variables", false, "find typos in variables only")
-	setExitStatus := flag.Bool("set_exit_status", false, "Set exit status to 1 if any issues are found")
 	flag.Usage = usage
-	flag.Parse()
+	opts, err := parseArgs(flag.CommandLine, os.Args[1:])
+	if err != nil {
+		log.Println(err)
+		os.Exit(2)
+	}
+
+	if opts.write {
+		os.Exit(runFix(opts.args, opts.flags))
+	}
+
+	os.Exit(run(opts, os.Stdout))
+}
+
+// options holds the settings for a run of identypo, combined from the configuration file and the command line.
+type options struct {
+	args          []string
+	flags         identypo.Flags
+	format        string
+	write         bool
+	baseline      string
+	writeBaseline bool
+	staleBaseline bool
+}
+
+// parseArgs parses the command line arguments into options. Settings from the configuration file (named by -config,
+// or found by walking up from the directory being checked) are applied first, so flags on the command line take precedence.
+func parseArgs(fs *flag.FlagSet, arguments []string) (options, error) {
+	ignores := fs.String("i", "", "ignore the following words requiring correction, comma separated (e.g. -i=\"nto,creater\")")
+	includeTests := fs.Bool("tests", true, "include test (*_test.go) files")
+	kinds := fs.String("kinds", "", "comma separated list of kinds of identifiers to check, all by default (e.g. -kinds=type,field), one of: "+strings.Join(identypo.Kinds, ", "))
+	functionsOnly := fs.Bool("functions", false, "find typos in functions only, the same as -kinds=func")
+	constantsOnly := fs.Bool("constants", false, "find typos in constants only, the same as -kinds=const")
+	variablesOnly := fs.Bool("variables", false, "find typos in variables only, the same as -kinds=var")
+	declarationsOnly := fs.Bool("declarations", false, "find typos only in identifiers declared in the checked packages, ignoring uses (such as calls into other packages)")
+	exportedOnly := fs.Bool("exported", false, "find typos only in the public API: exported package-level declarations, exported methods and exported struct fields")
+	setExitStatus := fs.Bool("set_exit_status", false, "Set exit status to 1 if any issues are found")
+	group := fs.Bool("group", true, "report each misspelled declaration once (with a count of its references), rather than once per use")
+	unusedSuppressions := fs.Bool("unused_suppressions", false, "report //identypo:ignore and //nolint:identypo comments that did not suppress anything")
+	write := fs.Bool("w", false, "rename misspelled declarations and their references to the corrected name, writing the changes to the source files")
+	format := fs.String("format", "text", "output format: text, json (a single array written to stdout) or sarif (a SARIF 2.1.0 log written to stdout)")
+	dict := fs.String("dict", "", "comma separated list of dictionary files with additional corrections, one \"wrong,right\" or \"wrong -> right\" pair per line")
+	words := fs.String("words", "", "comma separated list of word list files (e.g. -words=/usr/share/dict/words), words that aren't in them but are close to a word that is are reported with the nearest words as suggestions")
+	editDistance := fs.Int("edit_distance", identypo.DefaultEditDistance, "largest number of edits (1 or 2) a word can be from a word in the -words lists to be reported, words shorter than 8 letters are only reported a single edit away")
+	acronyms := fs.String("acronyms", "", "comma separated list of acronyms to recognize when splitting identifiers into words, in addition to golint's common initialisms (e.g. -acronyms=gRPC,IPv6)")
+	segment := fs.Bool("segment", false, "also find typos in words that run together in lower case, such as the \"recieved\" of recieveddata")
+	segmentConfidence := fs.Float64("segment_confidence", identypo.DefaultSegmentConfidence, "confidence (between 0 and 1) a split of run together words needs for its typos to be reported with -segment, higher values report fewer typos")
+	consistency := fs.Bool("consistency", false, "also report words spelled more than one way across the checked identifiers (e.g. Canceled where Cancelled is used more often), suggesting the spelling used most often")
+	locale := fs.String("locale", "", "enforce US or UK spellings (e.g. -locale=US reports \"Colour\" in DefaultColourScheme), by default either is accepted")
+	baseline := fs.String("baseline", "", "path to a baseline file, only findings that are not in the baseline are reported")
+	writeBaseline := fs.Bool("write_baseline", false, "write every current finding to the -baseline file instead of reporting them")
+	staleBaseline := fs.Bool("stale_baseline", false, "list entries in the -baseline file that no longer match any finding")
+	diff := fs.String("diff", "", "path to a unified diff (\"-\" for stdin), only typos in identifiers on lines it adds are reported")
+	since := fs.String("since", "", "git revision, only typos in identifiers on lines added since it (by git diff <rev>) are reported")
+	workers := fs.Int("j", 0, "number of files parsed, and identifiers checked, concurrently (0 = number of CPUs)")
+	cache := fs.String("cache", "", "path to a file caching the misspelled words of each file between runs, so unchanged files aren't checked again")
+	config := fs.String("config", "", "path to a configuration file (by default, .identypo.yml is searched for in the checked directory and its parents)")
+	if err := fs.Parse(arguments); err != nil {
+		return options{}, err
+	}
+
+	opts := options{
+		args: fs.Args(),
+		flags: identypo.Flags{
+			IncludeTests:       true,
+			GroupByDeclaration: true,
+		},
+		format: "text",
+	}
+
+	configFile := *config
+	if configFile == "" {
+		var err error
+		configFile, err = identypo.FindConfig(configDir(opts.args))
+		if err != nil {
+			return options{}, err
+		}
+	}
+
+	if configFile != "" {
+		cfg, err := identypo.LoadConfig(configFile)
+		if err != nil {
+			return options{}, err
+		}
+		cfg.Apply(&opts.flags)
+		if cfg.Format != "" {
+			opts.format = cfg.Format
+		}
+		opts.baseline = cfg.Baseline
+	}
+
+	kindsSet := false
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "i":
+			opts.flags.Ignores = *ignores
+		case "tests":
+			opts.flags.IncludeTests = *includeTests
+		case "kinds", "functions", "constants", "variables":
+			kindsSet = true
+		case "group":
+			opts.flags.GroupByDeclaration = *group
+		case "format":
+			opts.format = *format
+		case "baseline":
+			opts.baseline = *baseline
+		case "edit_distance":
+			opts.flags.EditDistance = *editDistance
+		case "segment":
+			opts.flags.Segment = *segment
+		case "segment_confidence":
+			opts.flags.SegmentConfidence = *segmentConfidence
+		case "consistency":
+			opts.flags.Consistency = *consistency
+		case "locale":
+			opts.flags.Locale = *locale
+		case "dict":
+			// dictionaries given on the command line are used along with those from the configuration file
+			opts.flags.Dictionaries = append(opts.flags.Dictionaries, strings.Split(*dict, ",")...)
+		case "words":
+			// and so are word lists
+			opts.flags.WordLists = append(opts.flags.WordLists, strings.Split(*words, ",")...)
+		case "acronyms":
+			// and acronyms
+			opts.flags.Acronyms = append(opts.flags.Acronyms, strings.Split(*acronyms, ",")...)
+		}
+	})
+
+	// kinds given on the command line replace the kinds from the configuration file, rather than adding to them
+	if kindsSet {
+		opts.flags.Kinds = nil
+		if *kinds != "" {
+			opts.flags.Kinds = strings.Split(*kinds, ",")
+		}
+		if *functionsOnly {
+			opts.flags.Kinds = append(opts.flags.Kinds, identypo.KindFunc)
+		}
+		if *constantsOnly {
+			opts.flags.Kinds = append(opts.flags.Kinds, identypo.KindConst)
+		}
+		if *variablesOnly {
+			opts.flags.Kinds = append(opts.flags.Kinds, identypo.KindVar)
+		}
+	}
+
+	opts.flags.DeclarationsOnly = *declarationsOnly
+	opts.flags.ExportedOnly = *exportedOnly
+	opts.flags.SetExitStatus = *setExitStatus
+	opts.flags.ReportUnusedSuppressions = *unusedSuppressions
+	opts.flags.Diff = *diff
+	opts.flags.Since = *since
+	opts.flags.Workers = *workers
+	opts.flags.Cache = *cache
+	opts.write = *write
+	opts.writeBaseline = *writeBaseline
+	opts.staleBaseline = *staleBaseline
+
+	if (opts.writeBaseline || opts.staleBaseline) && opts.baseline == "" {
+		return options{}, errors.New("-write_baseline and -stale_baseline require a -baseline file")
+	}
+
+	return opts, nil
+}
+
+// configDir returns the directory to start searching for a configuration file from, based on the first argument.
+func configDir(args []string) string {
+	if len(args) == 0 {
+		return "."
+	}
 
-	flags := identypo.Flags{
-		Ignores:       *ignores,
-		IncludeTests:  *includeTests,
-		FunctionsOnly: *functionsOnly,
-		ConstantsOnly: *constantsOnly,
-		VariablesOnly: *variablesOnly,
-		SetExitStatus: *setExitStatus,
+	dir := strings.TrimSuffix(args[0], "...")
+	if fi, err := os.Stat(dir); err == nil {
+		if fi.IsDir() {
+			return dir
+		}
+		return filepath.Dir(dir)
 	}
 
-	if err := identypo.CheckForIdentiferTypos(flag.Args(), flags); err != nil {
+	// import paths are resolved by the go command, so start from the working directory
+	return "."
+}
+
+// runFix renames misspelled declarations in args, reports each rename and returns the exit status for the process,
+// 2 if the packages couldn't be loaded. With set_exit_status, any rename that had to be refused is treated as an issue.
+func runFix(args []string, flags identypo.Flags) int {
+	renames, err := identypo.FixIdentifierTypos(args, flags)
+	if err != nil {
+		log.Println(err)
+		return 2
+	}
+
+	exitStatus := 0
+	for _, r := range renames {
+		log.Println(r)
+		if r.Err != nil && flags.SetExitStatus {
+			exitStatus = 1
+		}
+	}
+
+	return exitStatus
+}
+
+// run checks opts.args for typos, reports any findings in opts.format and returns the exit status for the process,
+// 2 on any error (such as invalid flags or packages that couldn't be loaded).
+// Text output is written with the log package, while json and sarif output is written to out. With a baseline,
+// only findings that are not in the baseline are reported (or, with write_baseline, every finding is written to it).
+func run(opts options, out io.Writer) int {
+	format, flags := opts.format, opts.flags
+	if format != "text" && format != "json" && format != "sarif" {
+		log.Printf("invalid format %q, must be text, json or sarif\n", format)
+		return 2
+	}
+
+	findings, checked, err := identypo.FindIdentifierTyposAndFiles(opts.args, flags)
+	if err != nil {
 		log.Println(err)
+		return 2
 	}
+
+	if opts.writeBaseline {
+		if err := writeBaseline(opts.baseline, findings); err != nil {
+			log.Println(err)
+			return 2
+		}
+		log.Printf("wrote %d findings to %v\n", len(findings), opts.baseline)
+		return 0
+	}
+
+	var stale []identypo.BaselineEntry
+	if opts.baseline != "" {
+		baseline, err := identypo.LoadBaseline(opts.baseline)
+		if err != nil {
+			log.Println(err)
+			return 2
+		}
+		findings, stale = baseline.Filter(findings, checked)
+	}
+
+	switch format {
+	case "json":
+		if err := identypo.WriteJSON(out, findings); err != nil {
+			log.Println(err)
+			return 2
+		}
+	case "sarif":
+		if err := identypo.WriteSARIF(out, findings); err != nil {
+			log.Println(err)
+			return 2
+		}
+	default:
+		for _, f := range findings {
+			log.Println(f)
+		}
+	}
+
+	if opts.staleBaseline {
+		for _, e := range stale {
+			log.Printf("stale baseline entry %v\n", e)
+		}
+	}
+
+	if flags.SetExitStatus && len(findings) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// writeBaseline writes a baseline of findings to filename, replacing any existing baseline.
+func writeBaseline(filename string, findings []identypo.Finding) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+
+	if err := identypo.WriteBaseline(f, filepath.Dir(filename), findings); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
 }
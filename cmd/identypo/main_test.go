@@ -0,0 +1,300 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/alexkohler/identypo"
+)
+
+func Test_run(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		flags      identypo.Flags
+		format     string
+		wantStatus int
+		wantOut    string
+	}{
+		{name: "issues found without set_exit_status",
+			args:       []string{"../../testdata/file.go"},
+			flags:      identypo.Flags{},
+			wantStatus: 0,
+		},
+		{name: "issues found with set_exit_status",
+			args:       []string{"../../testdata/file.go"},
+			flags:      identypo.Flags{SetExitStatus: true},
+			wantStatus: 1,
+		},
+		{name: "no issues found with set_exit_status",
+			args:       []string{"main.go"},
+			flags:      identypo.Flags{SetExitStatus: true},
+			wantStatus: 0,
+		},
+		{name: "no issues found with json format",
+			args:       []string{"main.go"},
+			flags:      identypo.Flags{SetExitStatus: true},
+			format:     "json",
+			wantStatus: 0,
+			wantOut:    "[]\n",
+		},
+		{name: "issues found with json format",
+			args:       []string{"../../testdata/file.go"},
+			flags:      identypo.Flags{SetExitStatus: true, Kinds: []string{identypo.KindConst}},
+			format:     "json",
+			wantStatus: 1,
+			wantOut:    "\"identifier\": \"constantSuccesful\"",
+		},
+		{name: "issues found with sarif format",
+			args:       []string{"../../testdata/file.go"},
+			flags:      identypo.Flags{SetExitStatus: true, Kinds: []string{identypo.KindConst}},
+			format:     "sarif",
+			wantStatus: 1,
+			wantOut:    "\"ruleId\": \"misspelled-declaration\"",
+		},
+		{name: "invalid format",
+			args:       []string{"main.go"},
+			format:     "xml",
+			wantStatus: 2,
+		},
+		{name: "invalid kinds",
+			args:       []string{"main.go"},
+			flags:      identypo.Flags{SetExitStatus: true, Kinds: []string{"procedure"}},
+			wantStatus: 2,
+		},
+		{name: "missing package",
+			args:       []string{"./nosuchpackage"},
+			flags:      identypo.Flags{SetExitStatus: true},
+			wantStatus: 2,
+		},
+		{name: "missing dictionary file",
+			args:       []string{"main.go"},
+			flags:      identypo.Flags{SetExitStatus: true, Dictionaries: []string{"nosuchdict.txt"}},
+			wantStatus: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			var buf bytes.Buffer
+			log.SetFlags(0)
+			log.SetOutput(&buf)
+			defer log.SetOutput(os.Stderr)
+
+			format := tt.format
+			if format == "" {
+				format = "text"
+			}
+
+			var out bytes.Buffer
+			if got := run(options{args: tt.args, flags: tt.flags, format: format}, &out); got != tt.wantStatus {
+				t.Fatalf("run() = %v, exp %v\n%v", got, tt.wantStatus, buf.String())
+			}
+
+			if !strings.Contains(out.String(), tt.wantOut) {
+				t.Fatalf("\ngot %v\nexp output containing %v\n", out.String(), tt.wantOut)
+			}
+		})
+	}
+}
+
+func Test_runBaseline(t *testing.T) {
+	dir := fixture(t, map[string]string{
+		"go.mod":        "module example.com/m\n",
+		".identypo.yml": "baseline: baseline.json\n",
+		"a/a.go":        "package a\n\nvar recieve, authorithy int\n",
+		"b/b.go":        "package b\n\nvar begining int\n",
+	})
+
+	var buf bytes.Buffer
+	log.SetFlags(0)
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	// runIn runs identypo from wd, where the baseline is found through the parent .identypo.yml
+	runIn := func(wd string, arguments ...string) int {
+		t.Chdir(wd)
+		opts, err := parseArgs(flag.NewFlagSet("identypo", flag.ContinueOnError), arguments)
+		if err != nil {
+			t.Fatalf("parseArgs %v", err)
+		}
+		buf.Reset()
+		return run(opts, io.Discard)
+	}
+
+	if got := runIn(dir, "-write_baseline", "./..."); got != 0 {
+		t.Fatalf("run() writing baseline = %v, exp 0\n%v", got, buf.String())
+	}
+
+	// entries are matched from a subdirectory, and entries for packages that weren't checked aren't stale
+	for _, wd := range []string{dir, filepath.Join(dir, "a"), filepath.Join(dir, "b")} {
+		if got := runIn(wd, "-stale_baseline", "-set_exit_status", "./..."); got != 0 {
+			t.Fatalf("run() in %v with baseline = %v, exp 0\n%v", wd, got, buf.String())
+		}
+		if buf.Len() != 0 {
+			t.Fatalf("expected no output in %v with an up to date baseline, got\n%v", wd, buf.String())
+		}
+	}
+
+	// drop one entry and add one that no longer matches anything
+	baseline := filepath.Join(dir, "baseline.json")
+	b, err := identypo.LoadBaseline(baseline)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.Entries = append(b.Entries[1:], identypo.BaselineEntry{Filename: "a/a.go", Identifier: "adress", Word: "adress"})
+	f, err := os.Create(baseline)
+	if err != nil {
+		t.Fatal(err)
+	}
+	json.NewEncoder(f).Encode(b.Entries)
+	f.Close()
+
+	if got := runIn(filepath.Join(dir, "a"), "-stale_baseline", "-set_exit_status", "."); got != 1 {
+		t.Fatalf("run() with outdated baseline = %v, exp 1\n%v", got, buf.String())
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	want := []string{
+		"a.go:3:14 \"authorithy\" should be authority in authorithy",
+		"stale baseline entry a/a.go: \"adress\" in adress",
+	}
+	if !reflect.DeepEqual(lines, want) {
+		t.Fatalf("\ngot %v\nexp %v\n", buf.String(), want)
+	}
+}
+
+func Test_parseArgs(t *testing.T) {
+	config := `
+ignore: [nto]
+kinds: [functions]
+tests: false
+format: json
+`
+	dir := fixture(t, map[string]string{".identypo.yml": config})
+	pkg := filepath.Join(dir, "pkg")
+	if err := os.Mkdir(pkg, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		arguments  []string
+		wantFlags  identypo.Flags
+		wantFormat string
+	}{
+		{name: "defaults without a config file",
+			arguments:  []string{"."},
+			wantFlags:  identypo.Flags{IncludeTests: true, GroupByDeclaration: true},
+			wantFormat: "text",
+		},
+		{name: "config file discovered from a parent directory",
+			arguments:  []string{pkg + "/..."},
+			wantFlags:  identypo.Flags{Ignores: "nto", Kinds: []string{"functions"}, GroupByDeclaration: true},
+			wantFormat: "json",
+		},
+		{name: "command line flags override the config file",
+			arguments:  []string{"-i=creater", "-tests", "-constants", "-format=text", pkg},
+			wantFlags:  identypo.Flags{Ignores: "creater", IncludeTests: true, Kinds: []string{"const"}, GroupByDeclaration: true},
+			wantFormat: "text",
+		},
+		{name: "kinds combined on the command line",
+			arguments:  []string{"-kinds=type,field", "-variables", "."},
+			wantFlags:  identypo.Flags{IncludeTests: true, GroupByDeclaration: true, Kinds: []string{"type", "field", "var"}},
+			wantFormat: "text",
+		},
+		{name: "dictionaries from the command line",
+			arguments:  []string{"-dict=words.txt,more.txt", "."},
+			wantFlags:  identypo.Flags{IncludeTests: true, GroupByDeclaration: true, Dictionaries: []string{"words.txt", "more.txt"}},
+			wantFormat: "text",
+		},
+		{name: "word lists from the command line",
+			arguments:  []string{"-words=/usr/share/dict/words,jargon.txt", "-edit_distance=1", "."},
+			wantFlags:  identypo.Flags{IncludeTests: true, GroupByDeclaration: true, WordLists: []string{"/usr/share/dict/words", "jargon.txt"}, EditDistance: 1},
+			wantFormat: "text",
+		},
+		{name: "acronyms from the command line",
+			arguments:  []string{"-acronyms=gRPC,IPv6", "."},
+			wantFlags:  identypo.Flags{IncludeTests: true, GroupByDeclaration: true, Acronyms: []string{"gRPC", "IPv6"}},
+			wantFormat: "text",
+		},
+		{name: "segmentation from the command line",
+			arguments:  []string{"-segment", "-segment_confidence=0.7", "."},
+			wantFlags:  identypo.Flags{IncludeTests: true, GroupByDeclaration: true, Segment: true, SegmentConfidence: 0.7},
+			wantFormat: "text",
+		},
+		{name: "consistency from the command line",
+			arguments:  []string{"-consistency", "."},
+			wantFlags:  identypo.Flags{IncludeTests: true, GroupByDeclaration: true, Consistency: true},
+			wantFormat: "text",
+		},
+		{name: "locale from the command line",
+			arguments:  []string{"-locale=US", "."},
+			wantFlags:  identypo.Flags{IncludeTests: true, GroupByDeclaration: true, Locale: "US"},
+			wantFormat: "text",
+		},
+		{name: "declarations only",
+			arguments:  []string{"-declarations", "."},
+			wantFlags:  identypo.Flags{IncludeTests: true, GroupByDeclaration: true, DeclarationsOnly: true},
+			wantFormat: "text",
+		},
+		{name: "exported only",
+			arguments:  []string{"-exported", "."},
+			wantFlags:  identypo.Flags{IncludeTests: true, GroupByDeclaration: true, ExportedOnly: true},
+			wantFormat: "text",
+		},
+		{name: "changes since a git revision",
+			arguments:  []string{"-since=origin/master", "."},
+			wantFlags:  identypo.Flags{IncludeTests: true, GroupByDeclaration: true, Since: "origin/master"},
+			wantFormat: "text",
+		},
+		{name: "explicit config file",
+			arguments:  []string{"-config=" + filepath.Join(dir, ".identypo.yml"), "-group=false", "."},
+			wantFlags:  identypo.Flags{Ignores: "nto", Kinds: []string{"functions"}},
+			wantFormat: "json",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := parseArgs(flag.NewFlagSet("identypo", flag.ContinueOnError), tt.arguments)
+			if err != nil {
+				t.Fatalf("parseArgs %v", err)
+			}
+
+			if !reflect.DeepEqual(opts.flags, tt.wantFlags) {
+				t.Fatalf("\ngot %+v\nexp %+v\n", opts.flags, tt.wantFlags)
+			}
+			if opts.format != tt.wantFormat {
+				t.Fatalf("format %v, exp %v", opts.format, tt.wantFormat)
+			}
+		})
+	}
+
+	if _, err := parseArgs(flag.NewFlagSet("identypo", flag.ContinueOnError), []string{"-write_baseline", "."}); err == nil {
+		t.Fatalf("expected error for -write_baseline without -baseline")
+	}
+}
+
+// fixture writes files, keyed by slash-separated names, to a temporary directory that's removed when the test
+// finishes, and returns the directory.
+func fixture(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, src := range files {
+		name = filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
@@ -0,0 +1,254 @@
+package identypo
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// ConfigFileNames are the names of the configuration files identypo looks for, in order of preference.
+var ConfigFileNames = []string{".identypo.yml", ".identypo.yaml"}
+
+// Config is a project configuration file (.identypo.yml). Every setting is optional; settings that are
+// left out keep their default (or command line) values.
+// * Ignore - corrections to be ignored, the same as Flags.Ignores.
+// * Corrections - additional corrections, keyed by misspelling (for example, tennant: tenant).
+// * Dictionaries - dictionary files of additional corrections, relative to the directory of the configuration file.
+// * WordLists, EditDistance - word list files, relative to the directory of the configuration file, and the largest
+// edit distance of the typos found with them, the same as Flags.WordLists and Flags.EditDistance.
+// * Acronyms - acronyms recognized when splitting identifiers into words, the same as Flags.Acronyms.
+// * Segment, SegmentConfidence - whether to find typos in words that run together, and the confidence needed to report
+// them, the same as Flags.Segment and Flags.SegmentConfidence.
+// * Consistency - whether to report words spelled more than one way, the same as Flags.Consistency.
+// * Locale - enforce US or UK spellings, the same as Flags.Locale.
+// * Kinds - kinds of identifiers to check, the same as Flags.Kinds. All identifiers are checked if empty.
+// * Include, Exclude - path globs restricting the files that are checked, relative to the directory of the
+// configuration file. See Flags.Include.
+// * Tests - whether to include test files.
+// * Baseline - baseline file of known findings for the identypo command, relative to the directory of the configuration file.
+// * Format - output format of the identypo command (text, json or sarif).
+type Config struct {
+	Ignore            []string          `yaml:"ignore"`
+	Corrections       map[string]string `yaml:"corrections"`
+	Dictionaries      []string          `yaml:"dictionaries"`
+	WordLists         []string          `yaml:"words"`
+	EditDistance      int               `yaml:"edit_distance"`
+	Acronyms          []string          `yaml:"acronyms"`
+	Segment           *bool             `yaml:"segment"`
+	SegmentConfidence float64           `yaml:"segment_confidence"`
+	Consistency       *bool             `yaml:"consistency"`
+	Locale            string            `yaml:"locale"`
+	Kinds             []string          `yaml:"kinds"`
+	Include           []string          `yaml:"include"`
+	Exclude           []string          `yaml:"exclude"`
+	Tests             *bool             `yaml:"tests"`
+	Baseline          string            `yaml:"baseline"`
+	Format            string            `yaml:"format"`
+
+	// dir is the directory of the configuration file
+	dir string
+}
+
+// FindConfig looks for a configuration file in dir and each of its parent directories, returning the path
+// of the first one found, or an empty string if there is none.
+func FindConfig(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		for _, name := range ConfigFileNames {
+			candidate := filepath.Join(dir, name)
+			if fi, err := os.Stat(candidate); err == nil && !fi.IsDir() {
+				return candidate, nil
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// LoadConfig reads and validates the configuration file at filename.
+func LoadConfig(filename string) (*Config, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg Config
+	if err := yaml.UnmarshalStrict(data, &cfg); err != nil {
+		return nil, fmt.Errorf("%v: %v", filename, err)
+	}
+
+	cfg.dir, err = filepath.Abs(filepath.Dir(filename))
+	if err != nil {
+		return nil, err
+	}
+
+	for i, dict := range cfg.Dictionaries {
+		if !filepath.IsAbs(dict) {
+			cfg.Dictionaries[i] = filepath.Join(filepath.Dir(filename), dict)
+		}
+	}
+	for i, list := range cfg.WordLists {
+		if !filepath.IsAbs(list) {
+			cfg.WordLists[i] = filepath.Join(filepath.Dir(filename), list)
+		}
+	}
+
+	if cfg.Baseline != "" && !filepath.IsAbs(cfg.Baseline) {
+		cfg.Baseline = filepath.Join(filepath.Dir(filename), cfg.Baseline)
+	}
+
+	if _, err := editDistance(cfg.EditDistance); err != nil {
+		return nil, fmt.Errorf("%v: %v", filename, err)
+	}
+
+	if _, err := acronymList(cfg.Acronyms); err != nil {
+		return nil, fmt.Errorf("%v: %v", filename, err)
+	}
+
+	if _, err := segmentConfidence(cfg.SegmentConfidence); err != nil {
+		return nil, fmt.Errorf("%v: %v", filename, err)
+	}
+
+	if _, err := localeRules(cfg.Locale); err != nil {
+		return nil, fmt.Errorf("%v: %v", filename, err)
+	}
+
+	if _, err := kindSet(cfg.Kinds); err != nil {
+		return nil, fmt.Errorf("%v: %v", filename, err)
+	}
+
+	for _, pattern := range append(cfg.Include, cfg.Exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("%v: invalid glob %q: %v", filename, pattern, err)
+		}
+	}
+
+	switch cfg.Format {
+	case "", "text", "json", "sarif":
+	default:
+		return nil, fmt.Errorf("%v: unknown format %q, must be text, json or sarif", filename, cfg.Format)
+	}
+
+	return &cfg, nil
+}
+
+// Apply sets the fields of flags that are specified in the configuration.
+func (c *Config) Apply(flags *Flags) {
+	if len(c.Ignore) > 0 {
+		flags.Ignores = strings.Join(c.Ignore, ",")
+	}
+
+	if len(c.Corrections) > 0 {
+		corrections := make(map[string]string, len(flags.Corrections)+len(c.Corrections))
+		for k, v := range flags.Corrections {
+			corrections[k] = v
+		}
+		for k, v := range c.Corrections {
+			corrections[k] = v
+		}
+		flags.Corrections = corrections
+	}
+
+	if len(c.Dictionaries) > 0 {
+		flags.Dictionaries = append(flags.Dictionaries, c.Dictionaries...)
+	}
+
+	if len(c.WordLists) > 0 {
+		flags.WordLists = append(flags.WordLists, c.WordLists...)
+	}
+	if c.EditDistance != 0 {
+		flags.EditDistance = c.EditDistance
+	}
+
+	if len(c.Acronyms) > 0 {
+		flags.Acronyms = append(flags.Acronyms, c.Acronyms...)
+	}
+
+	if c.Segment != nil {
+		flags.Segment = *c.Segment
+	}
+	if c.SegmentConfidence != 0 {
+		flags.SegmentConfidence = c.SegmentConfidence
+	}
+
+	if c.Consistency != nil {
+		flags.Consistency = *c.Consistency
+	}
+
+	if c.Locale != "" {
+		flags.Locale = c.Locale
+	}
+
+	if len(c.Kinds) > 0 {
+		flags.Kinds = c.Kinds
+	}
+
+	if len(c.Include) > 0 {
+		flags.Include = c.Include
+		flags.GlobDir = c.dir
+	}
+	if len(c.Exclude) > 0 {
+		flags.Exclude = c.Exclude
+		flags.GlobDir = c.dir
+	}
+
+	if c.Tests != nil {
+		flags.IncludeTests = *c.Tests
+	}
+}
+
+// excluded reports whether filename is left out by the include and exclude globs in flags. Files under
+// flags.GlobDir (or the working directory) are matched by their path relative to it.
+func (flags Flags) excluded(filename string) bool {
+	if len(flags.Include) == 0 && len(flags.Exclude) == 0 {
+		return false
+	}
+
+	dir := flags.GlobDir
+	if dir == "" {
+		dir = "."
+	}
+	if abs, err := filepath.Abs(filename); err == nil {
+		if absDir, err := filepath.Abs(dir); err == nil {
+			if rel, err := filepath.Rel(absDir, abs); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				filename = rel
+			}
+		}
+	}
+
+	if len(flags.Include) > 0 && !matchAny(flags.Include, filename) {
+		return true
+	}
+	return matchAny(flags.Exclude, filename)
+}
+
+// matchAny reports whether any of patterns matches filename or one of its parent directories, either by
+// full path or by base name. Patterns use forward slashes, whatever the operating system.
+func matchAny(patterns []string, filename string) bool {
+	filename = filepath.ToSlash(filepath.Clean(filename))
+
+	for _, pattern := range patterns {
+		for p := filename; p != "." && p != "/"; p = path.Dir(p) {
+			if ok, _ := path.Match(pattern, p); ok {
+				return true
+			}
+			if ok, _ := path.Match(pattern, path.Base(p)); ok {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,187 @@
+package identypo
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func Test_FindConfig(t *testing.T) {
+	root, _ := fixture(t, map[string]string{
+		".identypo.yml":    "ignore: [nto]\n",
+		"a/.identypo.yaml": "ignore: [nto]\n",
+	})
+
+	nested := filepath.Join(root, "a", "b", "c")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	rootConfig := filepath.Join(root, ".identypo.yml")
+	nestedConfig := filepath.Join(root, "a", ".identypo.yaml")
+
+	tests := []struct {
+		name string
+		dir  string
+		want string
+	}{
+		{name: "config in directory", dir: root, want: rootConfig},
+		{name: "config in nearest parent", dir: nested, want: nestedConfig},
+		{name: "config in parent of nested config", dir: filepath.Join(root, "a", ".."), want: rootConfig},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FindConfig(tt.dir)
+			if err != nil {
+				t.Fatalf("FindConfig %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("\ngot %v\nexp %v\n", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_LoadConfig(t *testing.T) {
+	dir, write := fixture(t, nil)
+
+	tests := []struct {
+		name    string
+		src     string
+		want    Flags
+		wantErr bool
+	}{
+		{name: "all settings",
+			src: `
+ignore: [nto, creater]
+corrections:
+  tennant: tenant
+kinds: [functions, constants, field]
+include: ["*.go"]
+exclude: [testdata, "*_gen.go"]
+tests: false
+format: json
+`,
+			want: Flags{
+				Ignores:      "nto,creater",
+				IncludeTests: false,
+				Kinds:        []string{"functions", "constants", "field"},
+				Corrections:  map[string]string{"tennant": "tenant"},
+				Include:      []string{"*.go"},
+				Exclude:      []string{"testdata", "*_gen.go"},
+				GlobDir:      dir,
+			},
+		},
+		{name: "settings left out keep their values",
+			src:  "ignore: [nto]\n",
+			want: Flags{Ignores: "nto", IncludeTests: true},
+		},
+		{name: "dictionaries relative to the config file",
+			src:  "dictionaries: [words.txt, /etc/identypo/words.txt]\n",
+			want: Flags{IncludeTests: true, Dictionaries: []string{filepath.Join(dir, "words.txt"), "/etc/identypo/words.txt"}},
+		},
+		{name: "word lists relative to the config file",
+			src:  "words: [words.txt, /usr/share/dict/words]\nedit_distance: 1\n",
+			want: Flags{IncludeTests: true, WordLists: []string{filepath.Join(dir, "words.txt"), "/usr/share/dict/words"}, EditDistance: 1},
+		},
+		{name: "consistency",
+			src:  "consistency: true\n",
+			want: Flags{IncludeTests: true, Consistency: true},
+		},
+		{name: "locale",
+			src:  "locale: UK\n",
+			want: Flags{IncludeTests: true, Locale: "UK"},
+		},
+		{name: "acronyms",
+			src:  "acronyms: [gRPC, IPv6]\n",
+			want: Flags{IncludeTests: true, Acronyms: []string{"gRPC", "IPv6"}},
+		},
+		{name: "segmentation",
+			src:  "segment: true\nsegment_confidence: 0.7\n",
+			want: Flags{IncludeTests: true, Segment: true, SegmentConfidence: 0.7},
+		},
+		{name: "unknown locale", src: "locale: NZ\n", wantErr: true},
+		{name: "invalid segment confidence", src: "segment_confidence: 2\n", wantErr: true},
+		{name: "invalid edit distance", src: "edit_distance: 3\n", wantErr: true},
+		{name: "invalid acronym", src: "acronyms: [\"I/O\"]\n", wantErr: true},
+		{name: "unknown setting", src: "ignores: [nto]\n", wantErr: true},
+		{name: "unknown kind", src: "kinds: [closures]\n", wantErr: true},
+		{name: "unknown format", src: "format: xml\n", wantErr: true},
+		{name: "invalid glob", src: "exclude: [\"[\"]\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			write(".identypo.yml", tt.src)
+
+			cfg, err := LoadConfig(filepath.Join(dir, ".identypo.yml"))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error loading %q", tt.src)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadConfig %v", err)
+			}
+
+			got := Flags{IncludeTests: true}
+			cfg.Apply(&got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("\ngot %+v\nexp %+v\n", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_excludedFromSubdirectory(t *testing.T) {
+	dir, _ := fixture(t, map[string]string{
+		"go.mod":                "module example.com/globs\n",
+		".identypo.yml":         "exclude: [internal/gen]\n",
+		"internal/file.go":      "package internal\n\nvar inital = 1\n",
+		"internal/gen/file.go":  "package gen\n\nvar begining = 1\n",
+		"internal/gen/other.go": "package gen\n\nvar succesful = 1\n",
+	})
+
+	// the exclude glob is relative to the configuration file, not the directory identypo is run from
+	defer inModule(t, filepath.Join(dir, "internal"))()
+
+	configFile, err := FindConfig(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var flags Flags
+	cfg.Apply(&flags)
+
+	findings, err := FindIdentifierTypos([]string{"./..."}, flags)
+	if err != nil {
+		t.Fatalf("FindIdentifierTypos %v", err)
+	}
+	if len(findings) != 1 || findings[0].Word != "inital" {
+		t.Fatalf("\ngot %v\nexp a single finding of inital\n", findings)
+	}
+}
+
+func Test_matchAny(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		filename string
+		want     bool
+	}{
+		{patterns: []string{"*_gen.go"}, filename: "pkg/api/types_gen.go", want: true},
+		{patterns: []string{"testdata"}, filename: "pkg/testdata/file.go", want: true},
+		{patterns: []string{"pkg/*"}, filename: "pkg/api/types.go", want: true},
+		{patterns: []string{"pkg/*/types.go"}, filename: "pkg/api/types.go", want: true},
+		{patterns: []string{"api"}, filename: "pkg/apis/types.go", want: false},
+		{patterns: nil, filename: "file.go", want: false},
+	}
+	for _, tt := range tests {
+		if got := matchAny(tt.patterns, tt.filename); got != tt.want {
+			t.Errorf("matchAny(%q, %q) = %v, exp %v", tt.patterns, tt.filename, got, tt.want)
+		}
+	}
+}
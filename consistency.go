@@ -0,0 +1,157 @@
+package identypo
+
+import (
+	"go/ast"
+	"go/token"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/client9/misspell"
+)
+
+// minVariantWord is the length of the shortest word compared with the other words of the checked identifiers for
+// variant spellings with a letter doubled, since shorter words (such as later and latter) are too often different
+// words.
+const minVariantWord = 6
+
+var (
+	americanSpellingsOnce sync.Once
+	americanSpellings     map[string]string
+)
+
+// americanSpelling returns the American spelling of w (initialize for initialise), or w itself if it's the same.
+func americanSpelling(w string) string {
+	americanSpellingsOnce.Do(func() {
+		americanSpellings = make(map[string]string, len(misspell.DictAmerican)/2)
+		for i := 0; i+1 < len(misspell.DictAmerican); i += 2 {
+			americanSpellings[misspell.DictAmerican[i]] = misspell.DictAmerican[i+1]
+		}
+	})
+	if american, ok := americanSpellings[w]; ok {
+		return american
+	}
+	return w
+}
+
+// spellings counts the number of times each spelling (in lower case) of a word is used in the checked identifiers,
+// and finds the spellings used less often than another spelling of the same word. known holds the known words,
+// including those of Flags.WordLists.
+type spellings struct {
+	counts map[string]int
+	known  *lexicon
+}
+
+// variant reports whether a is a variant spelling of b, a different spelling of the same word: either the British or
+// American spelling of it (cancelled and canceled), or, if b is a known word and a isn't, b with a letter doubled or
+// undoubled (unmarshaller and unmarshaler). Words starting with a doubled letter (such as mmapped) are left alone,
+// since they're usually a word with a prefix, as are words shorter than minVariantWord letters.
+func (s *spellings) variant(a, b string) bool {
+	if americanSpelling(a) == americanSpelling(b) {
+		return true
+	}
+	if len(a) < minVariantWord || len(b) < minVariantWord || a[0] == a[1] || b[0] == b[1] {
+		return false
+	}
+	if len(a) != len(b)+1 && len(b) != len(a)+1 {
+		return false
+	}
+	return undoubled(a) == undoubled(b) && !s.known.known(a) && s.known.known(b)
+}
+
+// undoubled returns w with every run of a repeated letter replaced by a single letter.
+func undoubled(w string) string {
+	b := make([]byte, 0, len(w))
+	for i := 0; i < len(w); i++ {
+		if i == 0 || w[i] != w[i-1] {
+			b = append(b, w[i])
+		}
+	}
+	return string(b)
+}
+
+// dominant returns the spelling each minority spelling should be: the variant of it (see variant) used most often,
+// if that's used more often than it. Spellings used as often as each other are both left alone.
+func (s *spellings) dominant() map[string]string {
+	words := make([]string, 0, len(s.counts))
+	for w := range s.counts {
+		words = append(words, w)
+	}
+	sort.Strings(words)
+
+	// variants share a key: their American spelling, or their spelling with doubled letters undoubled
+	keys := make(map[string][]string)
+	for _, w := range words {
+		american := "=" + americanSpelling(w)
+		keys[american] = append(keys[american], w)
+		if len(w) >= minVariantWord {
+			keys[undoubled(w)] = append(keys[undoubled(w)], w)
+		}
+	}
+
+	dominant := make(map[string]string)
+	for _, spellings := range keys {
+		for _, a := range spellings {
+			for _, b := range spellings {
+				if a == b || s.counts[b] <= s.counts[a] || !s.variant(a, b) {
+					continue
+				}
+				// of the variants used most often, the first alphabetically is picked, so the result doesn't
+				// depend on map order
+				if d, ok := dominant[a]; !ok || s.counts[b] > s.counts[d] || s.counts[b] == s.counts[d] && b < d {
+					dominant[a] = b
+				}
+			}
+		}
+	}
+	return dominant
+}
+
+// inconsistencies returns the findings for the words of each of idents spelled differently from the way that word
+// is spelled most often in idents (see spellings), for example "Canceled" in StatusCanceled when every other
+// identifier has Cancelled. Words found to be misspelled aren't counted, since they're reported anyway.
+func (c *checker) inconsistencies(fset *token.FileSet, idents []*ast.Ident) [][]Finding {
+	s := &spellings{
+		counts: make(map[string]int),
+		known:  newLexicon(c.flags.words, c.replacer.Replacements, c.acronyms, 0),
+	}
+
+	words := make([][]word, len(idents))
+	for i, ident := range idents {
+		filename := ""
+		if tf := fset.File(ident.Pos()); tf != nil {
+			filename = tf.Name()
+		}
+
+		for _, w := range splitIdentifier(ident.Name, c.acronyms) {
+			// only words of letters are compared, in any case
+			if !isLowerRun(strings.ToLower(w.text)) || c.replace(filename, w.text).misspelled {
+				continue
+			}
+			words[i] = append(words[i], w)
+			s.counts[strings.ToLower(w.text)]++
+		}
+	}
+
+	dominant := s.dominant()
+	if len(dominant) == 0 {
+		return nil
+	}
+
+	findings := make([][]Finding, len(idents))
+	for i, ident := range idents {
+		for _, w := range words[i] {
+			d, ok := dominant[strings.ToLower(w.text)]
+			if !ok {
+				continue
+			}
+			kind, ok := c.reportedKind(fset, ident)
+			if !ok {
+				break
+			}
+			m := misspelling{word: w, correction: matchCase(w.text, d), inconsistent: true}
+			findings[i] = append(findings[i], c.finding(fset, ident, kind, m))
+		}
+	}
+	return findings
+}
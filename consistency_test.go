@@ -0,0 +1,107 @@
+package identypo
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"testing"
+)
+
+func Test_spellingsDominant(t *testing.T) {
+	c := newChecker(Flags{})
+
+	tests := []struct {
+		name   string
+		counts map[string]int
+		want   map[string]string
+	}{
+		{name: "British and American spellings",
+			counts: map[string]int{"cancelled": 3, "canceled": 1, "initialise": 1, "initialize": 2},
+			want:   map[string]string{"canceled": "cancelled", "initialise": "initialize"},
+		},
+		{name: "spellings used as often",
+			counts: map[string]int{"colour": 2, "color": 2},
+			want:   map[string]string{},
+		},
+		{name: "a doubled letter",
+			counts: map[string]int{"unmarshaller": 1, "unmarshaler": 4},
+			want:   map[string]string{"unmarshaller": "unmarshaler"},
+		},
+		{name: "the known spelling is used less often",
+			counts: map[string]int{"unmarshaller": 4, "unmarshaler": 1},
+			want:   map[string]string{},
+		},
+		{name: "different words",
+			counts: map[string]int{"filling": 1, "filing": 3, "mmapped": 1, "mapped": 3, "later": 1, "latter": 3},
+			want:   map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &spellings{counts: tt.counts, known: newLexicon(nil, c.replacer.Replacements, c.acronyms, 0)}
+			if got := s.dominant(); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("\ngot %v\nexp %v\n", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_findTyposConsistency(t *testing.T) {
+	src := `package main
+
+type Status int
+
+const (
+	StatusCancelled Status = iota
+	StatusRecieved
+)
+
+func isCancelled(s Status) bool { return s == StatusCancelled }
+
+func cancelledOrCanceled(canceled bool) {}
+`
+	tests := []struct {
+		name  string
+		flags Flags
+		want  []string
+	}{
+		{name: "without consistency",
+			flags: Flags{},
+			want: []string{
+				"file.go:7:8 \"Recieved\" should be Received in StatusRecieved",
+			},
+		},
+		{name: "with consistency",
+			flags: Flags{Consistency: true},
+			want: []string{
+				"file.go:7:8 \"Recieved\" should be Received in StatusRecieved",
+				"file.go:12:17 \"Canceled\" should be Cancelled (as spelled elsewhere) in cancelledOrCanceled",
+				"file.go:12:26 \"canceled\" should be cancelled (as spelled elsewhere) in canceled",
+			},
+		},
+		{name: "only in the checked kinds",
+			flags: Flags{Consistency: true, Kinds: []string{KindVar}},
+			want: []string{
+				"file.go:12:26 \"canceled\" should be cancelled (as spelled elsewhere) in canceled",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fset := token.NewFileSet()
+			f, err := parser.ParseFile(fset, "file.go", src, 0)
+			if err != nil {
+				t.Fatalf("Did not expect error parsing file, %v", err)
+			}
+
+			var got []string
+			for _, finding := range findTypos(fset, []*ast.File{f}, nil, tt.flags) {
+				got = append(got, finding.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("\ngot %q\nexp %q\n", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package identypo
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// DictionaryError describes the malformed lines of a dictionary file.
+type DictionaryError struct {
+	Filename string
+	Lines    []DictionaryLineError
+}
+
+// DictionaryLineError describes a single malformed line of a dictionary file.
+type DictionaryLineError struct {
+	Line int
+	Text string
+	Err  string
+}
+
+func (e *DictionaryError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%v: %d malformed line(s)", e.Filename, len(e.Lines))
+	for _, l := range e.Lines {
+		fmt.Fprintf(&b, "\n%v:%v: %v: %q", e.Filename, l.Line, l.Err, l.Text)
+	}
+	return b.String()
+}
+
+// LoadDictionary reads a dictionary file of additional corrections. See ParseDictionary for the format.
+func LoadDictionary(filename string) (map[string]string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ParseDictionary(filename, f)
+}
+
+// ParseDictionary parses additional corrections, one per line, in either the "wrong,right" or "wrong -> right" form.
+// Blank lines and lines starting with # are ignored. Misspellings are keyed in lower case. If any line is malformed,
+// a *DictionaryError listing every malformed line (by number) is returned.
+func ParseDictionary(filename string, r io.Reader) (map[string]string, error) {
+	corrections := make(map[string]string)
+	dictErr := &DictionaryError{Filename: filename}
+
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		wrong, right, err := parseCorrection(text)
+		if err == "" {
+			if existing, ok := corrections[wrong]; ok && existing != right {
+				err = fmt.Sprintf("%v is already corrected to %v", wrong, existing)
+			}
+		}
+		if err != "" {
+			dictErr.Lines = append(dictErr.Lines, DictionaryLineError{Line: line, Text: scanner.Text(), Err: err})
+			continue
+		}
+
+		corrections[wrong] = right
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(dictErr.Lines) > 0 {
+		return nil, dictErr
+	}
+
+	return corrections, nil
+}
+
+// parseCorrection parses a single dictionary line, returning a description of the problem if it's malformed.
+func parseCorrection(text string) (string, string, string) {
+	var parts []string
+	if strings.Contains(text, "->") {
+		parts = strings.Split(text, "->")
+	} else {
+		parts = strings.Split(text, ",")
+	}
+
+	if len(parts) != 2 {
+		return "", "", `expected "wrong,right" or "wrong -> right"`
+	}
+
+	wrong := strings.ToLower(strings.TrimSpace(parts[0]))
+	right := strings.ToLower(strings.TrimSpace(parts[1]))
+
+	for _, word := range []string{wrong, right} {
+		if word == "" {
+			return "", "", "missing word"
+		}
+		for _, r := range word {
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\'' && r != '-' {
+				return "", "", fmt.Sprintf("%q is not a single word", word)
+			}
+		}
+	}
+
+	if wrong == right {
+		return "", "", "correction is the same as the misspelling"
+	}
+
+	return wrong, right, ""
+}
+
+// loadDictionaries returns a copy of flags with the corrections from flags.Dictionaries added to flags.Corrections.
+// Corrections already in flags.Corrections take precedence over those from dictionary files.
+func (flags Flags) loadDictionaries() (Flags, error) {
+	if len(flags.Dictionaries) == 0 {
+		return flags, nil
+	}
+
+	corrections := make(map[string]string)
+	for _, filename := range flags.Dictionaries {
+		dict, err := LoadDictionary(filename)
+		if err != nil {
+			return flags, err
+		}
+		for k, v := range dict {
+			corrections[k] = v
+		}
+	}
+
+	for k, v := range flags.Corrections {
+		corrections[k] = v
+	}
+	flags.Corrections = corrections
+
+	return flags, nil
+}
@@ -0,0 +1,146 @@
+package identypo
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_ParseDictionary(t *testing.T) {
+	tests := []struct {
+		name      string
+		src       string
+		want      map[string]string
+		wantLines []int
+	}{
+		{name: "both formats",
+			src:  "tennant,tenant\nidempotant -> idempotent\n",
+			want: map[string]string{"tennant": "tenant", "idempotant": "idempotent"},
+		},
+		{name: "comments, blank lines and surrounding spaces",
+			src:  "# project words\n\n  Tennant , Tenant  \n\t# more\nrecieve->receive\n",
+			want: map[string]string{"tennant": "tenant", "recieve": "receive"},
+		},
+		{name: "repeated correction",
+			src:  "tennant,tenant\ntennant -> tenant\n",
+			want: map[string]string{"tennant": "tenant"},
+		},
+		{name: "hyphenated correction",
+			src:  "alltime,all-time\n",
+			want: map[string]string{"alltime": "all-time"},
+		},
+		{name: "empty",
+			src:  "",
+			want: map[string]string{},
+		},
+		{name: "malformed lines",
+			src:       "tennant\ntennant,tenant,tenants\n,tenant\ntennant ->\nten nant,tenant\nok,ok\ngood -> fine\n",
+			wantLines: []int{1, 2, 3, 4, 5, 6},
+		},
+		{name: "conflicting corrections",
+			src:       "tennant,tenant\ntennant,tennis\n",
+			wantLines: []int{2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDictionary("words.txt", strings.NewReader(tt.src))
+			if len(tt.wantLines) > 0 {
+				dictErr, ok := err.(*DictionaryError)
+				if !ok {
+					t.Fatalf("expected *DictionaryError, got %v", err)
+				}
+				var lines []int
+				for _, l := range dictErr.Lines {
+					lines = append(lines, l.Line)
+				}
+				if !reflect.DeepEqual(lines, tt.wantLines) {
+					t.Fatalf("malformed lines\ngot %v\nexp %v\n%v", lines, tt.wantLines, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseDictionary %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("\ngot %v\nexp %v\n", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_findTyposDictionaries(t *testing.T) {
+	// misspell already corrects adress, but a dictionary entry for it must not be shadowed by misspell's adres
+	dir, _ := fixture(t, map[string]string{
+		"words.txt":     "# project words\ntennant -> tenant\nidempotant,idempotent\n",
+		"override.txt":  "Adress -> address\n",
+		"malformed.txt": "tennant\n",
+	})
+	dict := filepath.Join(dir, "words.txt")
+	override := filepath.Join(dir, "override.txt")
+	malformed := filepath.Join(dir, "malformed.txt")
+
+	tests := []struct {
+		name    string
+		flags   Flags
+		want    []string
+		wantErr bool
+	}{
+		{name: "corrections from dictionary",
+			flags: Flags{Dictionaries: []string{dict}},
+			want:  []string{"file.go:1:19 \"tennant\" should be tenant in tennantID", "file.go:1:32 \"Idempotant\" should be Idempotent in isIdempotant", "file.go:1:44 \"adress\" should be address in adressBook"},
+		},
+		{name: "explicit corrections take precedence",
+			flags: Flags{Dictionaries: []string{dict}, Corrections: map[string]string{"tennant": "tenet"}},
+			want:  []string{"file.go:1:19 \"tennant\" should be tenet in tennantID", "file.go:1:32 \"Idempotant\" should be Idempotent in isIdempotant", "file.go:1:44 \"adress\" should be address in adressBook"},
+		},
+		{name: "dictionary replacing misspell's correction",
+			flags: Flags{Dictionaries: []string{override}},
+			want:  []string{"file.go:1:44 \"adress\" should be address in adressBook"},
+		},
+		{name: "no dictionary",
+			flags: Flags{},
+			want:  []string{"file.go:1:44 \"adress\" should be address in adressBook"},
+		},
+		{name: "missing dictionary",
+			flags:   Flags{Dictionaries: []string{filepath.Join(dir, "missing.txt")}},
+			wantErr: true,
+		},
+		{name: "malformed dictionary",
+			flags:   Flags{Dictionaries: []string{malformed}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flags, err := tt.flags.loadDictionaries()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error loading %v", tt.flags.Dictionaries)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("loadDictionaries %v", err)
+			}
+
+			fset := token.NewFileSet()
+			f, err := parser.ParseFile(fset, "file.go", `package main; var tennantID, isIdempotant, adressBook int`, 0)
+			if err != nil {
+				t.Fatalf("Did not expect error parsing file, %v", err)
+			}
+
+			var got []string
+			for _, finding := range findTypos(fset, []*ast.File{f}, nil, flags) {
+				got = append(got, finding.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("\ngot %q\nexp %q\n", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package identypo
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// addedLines holds the line numbers added by a unified diff, keyed by the absolute path (with symlinks resolved)
+// of the file they were added to.
+type addedLines map[string]map[int]bool
+
+// loadDiff returns the lines added by the diff in flags.Diff (read from stdin if it's "-"), or by the changes
+// since the git revision flags.Since. Returns nil if neither is set.
+func (flags Flags) loadDiff() (addedLines, error) {
+	switch {
+	case flags.Diff != "" && flags.Since != "":
+		return nil, fmt.Errorf("only one of Diff and Since may be set")
+	case flags.Diff == "-":
+		return parseDiff(os.Stdin, diffRoot())
+	case flags.Diff != "":
+		f, err := os.Open(flags.Diff)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return parseDiff(f, diffRoot())
+	case flags.Since != "":
+		return gitDiff(".", flags.Since)
+	}
+	return nil, nil
+}
+
+// diffRoot returns the directory the file names in a diff are relative to: the root of the git repository containing
+// the working directory (as git writes them, from whichever directory it's run), or the working directory outside of one.
+func diffRoot() string {
+	if root, err := git(".", "rev-parse", "--show-toplevel"); err == nil {
+		return strings.TrimSpace(root)
+	}
+	return "."
+}
+
+// gitDiff returns the lines added to the working tree containing dir since the git revision rev. Untracked files
+// are not part of git's diff, so nothing in them is considered added.
+func gitDiff(dir, rev string) (addedLines, error) {
+	root, err := git(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := git(dir, "diff", "--no-color", "--no-ext-diff", "--unified=0", "--src-prefix=a/", "--dst-prefix=b/", rev, "--")
+	if err != nil {
+		return nil, err
+	}
+
+	return parseDiff(strings.NewReader(out), strings.TrimSpace(root))
+}
+
+// git runs git with args in dir and returns its output.
+func git(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %v: %v: %v", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
+
+// parseDiff returns the lines added by the unified diff read from r. File names in the diff are relative to root
+// (or to the working directory, for files that only exist there), and a leading "b/" (as written by git) is removed.
+func parseDiff(r io.Reader, root string) (addedLines, error) {
+	added := make(addedLines)
+
+	var lines map[int]bool
+	var line, oldRemaining, newRemaining int
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		text := scanner.Text()
+
+		// inside a hunk, every line is part of it until both sides have been consumed
+		if oldRemaining > 0 || newRemaining > 0 {
+			switch {
+			case strings.HasPrefix(text, "+"):
+				if lines != nil {
+					lines[line] = true
+				}
+				line++
+				newRemaining--
+			case strings.HasPrefix(text, "-"):
+				oldRemaining--
+			case strings.HasPrefix(text, "\\"):
+				// "\ No newline at end of file"
+			default:
+				line++
+				oldRemaining--
+				newRemaining--
+			}
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(text, "+++ "):
+			name := strings.TrimPrefix(text, "+++ ")
+			if i := strings.IndexByte(name, '\t'); i >= 0 {
+				name = name[:i]
+			}
+			if name == "/dev/null" {
+				lines = nil
+				continue
+			}
+			name = filepath.FromSlash(strings.TrimPrefix(name, "b/"))
+			path := filepath.Join(root, name)
+			if _, err := os.Stat(path); err != nil {
+				if _, err := os.Stat(name); err == nil {
+					path = name
+				}
+			}
+			key, err := resolvePath(path)
+			if err != nil {
+				return nil, err
+			}
+			if added[key] == nil {
+				added[key] = make(map[int]bool)
+			}
+			lines = added[key]
+
+		case strings.HasPrefix(text, "@@ "):
+			var err error
+			line, oldRemaining, newRemaining, err = parseHunkHeader(text)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return added, nil
+}
+
+// parseHunkHeader parses a hunk header of the form "@@ -l,s +l,s @@", returning the first line of the new file
+// and the number of lines the hunk spans in the old and new files.
+func parseHunkHeader(text string) (int, int, int, error) {
+	fields := strings.Fields(text)
+	if len(fields) < 4 || !strings.HasPrefix(fields[1], "-") || !strings.HasPrefix(fields[2], "+") {
+		return 0, 0, 0, fmt.Errorf("malformed hunk header %q", text)
+	}
+
+	_, oldCount, err := parseRange(fields[1][1:])
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("malformed hunk header %q: %v", text, err)
+	}
+	start, newCount, err := parseRange(fields[2][1:])
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("malformed hunk header %q: %v", text, err)
+	}
+
+	return start, oldCount, newCount, nil
+}
+
+// parseRange parses "l,s" (or just "l", for a single line) from a hunk header.
+func parseRange(text string) (int, int, error) {
+	count := 1
+	if i := strings.IndexByte(text, ','); i >= 0 {
+		var err error
+		if count, err = strconv.Atoi(text[i+1:]); err != nil {
+			return 0, 0, err
+		}
+		text = text[:i]
+	}
+
+	start, err := strconv.Atoi(text)
+	return start, count, err
+}
+
+// resolvePath returns the absolute path of filename with any symlinks in its directory resolved, so the
+// same file is found whether it's named relative to the working directory or to the root of a repository.
+func resolvePath(filename string) (string, error) {
+	filename, err := filepath.Abs(filename)
+	if err != nil {
+		return "", err
+	}
+
+	if dir, err := filepath.EvalSymlinks(filepath.Dir(filename)); err == nil {
+		filename = filepath.Join(dir, filepath.Base(filename))
+	}
+	return filename, nil
+}
+
+// file returns the lines added to filename.
+func (a addedLines) file(filename string) map[int]bool {
+	key, err := resolvePath(filename)
+	if err != nil {
+		return nil
+	}
+	return a[key]
+}
@@ -0,0 +1,225 @@
+package identypo
+
+import (
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_parseDiff(t *testing.T) {
+	root := t.TempDir()
+
+	tests := []struct {
+		name string
+		diff string
+		want map[string][]int
+	}{
+		{name: "git diff with context",
+			diff: `diff --git a/pkg/a.go b/pkg/a.go
+index 1111111..2222222 100644
+--- a/pkg/a.go
++++ b/pkg/a.go
+@@ -1,4 +1,5 @@
+ package pkg
+-var old int
++var recieve int
++var tennant int
+
+ func f() {}
+@@ -10,2 +11,3 @@ func g() {
+ 	x := 1
++++	y := 2
+ 	_ = x
+`,
+			want: map[string][]int{"pkg/a.go": {2, 3, 12}},
+		},
+		{name: "new and deleted files",
+			diff: `--- /dev/null
++++ b/new.go
+@@ -0,0 +1,2 @@
++package pkg
++var recieve int
+--- a/deleted.go
++++ /dev/null
+@@ -1 +0,0 @@
+-package pkg
+`,
+			want: map[string][]int{"new.go": {1, 2}},
+		},
+		{name: "diff -u with timestamps and no newline marker",
+			diff: `--- a.go	2020-01-01 00:00:00.000000000 +0000
++++ a.go	2020-01-02 00:00:00.000000000 +0000
+@@ -1 +1 @@
+-var a int
+\ No newline at end of file
++var recieve int
+\ No newline at end of file
+`,
+			want: map[string][]int{"a.go": {1}},
+		},
+		{name: "only removed lines",
+			diff: `--- a/a.go
++++ b/a.go
+@@ -3,1 +2,0 @@
+-var recieve int
+`,
+			want: map[string][]int{"a.go": nil},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDiff(strings.NewReader(tt.diff), root)
+			if err != nil {
+				t.Fatalf("parseDiff %v", err)
+			}
+
+			want := make(addedLines)
+			for name, lines := range tt.want {
+				key, err := resolvePath(filepath.Join(root, name))
+				if err != nil {
+					t.Fatal(err)
+				}
+				want[key] = make(map[int]bool)
+				for _, l := range lines {
+					want[key][l] = true
+				}
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("\ngot %v\nexp %v\n", got, want)
+			}
+		})
+	}
+
+	if _, err := parseDiff(strings.NewReader("+++ b/a.go\n@@ -1 +x @@\n"), root); err == nil {
+		t.Fatalf("expected error for malformed hunk header")
+	}
+}
+
+func Test_FindIdentifierTyposSince(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+
+	dir, write := fixture(t, map[string]string{
+		"go.mod": "module example.com/fixture\n",
+		"a.go":   "package fixture\n\nvar begining int\n\nfunc f() int { return begining }\n",
+	})
+
+	runGit := func(args ...string) {
+		t.Helper()
+		args = append([]string{"-c", "user.name=identypo", "-c", "user.email=identypo@example.com"}, args...)
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	runGit("init", "-q")
+	runGit("add", ".")
+	runGit("commit", "-q", "-m", "initial")
+
+	write("a.go", "package fixture\n\nvar begining int\n\nvar recieved int\n\nfunc f() int { return begining + recieved }\n\nfunc g() int { return begining }\n")
+
+	defer inModule(t, dir)()
+
+	tests := []struct {
+		name  string
+		flags Flags
+		want  []string
+	}{
+		{name: "without a diff",
+			flags: Flags{},
+			want: []string{
+				"a.go:3:5 \"begining\" should be beginning in begining",
+				"a.go:5:5 \"recieved\" should be received in recieved",
+				"a.go:7:23 \"begining\" should be beginning in begining",
+				"a.go:7:34 \"recieved\" should be received in recieved",
+				"a.go:9:23 \"begining\" should be beginning in begining",
+			},
+		},
+		{name: "since HEAD",
+			flags: Flags{Since: "HEAD"},
+			want: []string{
+				"a.go:5:5 \"recieved\" should be received in recieved",
+				"a.go:7:23 \"begining\" should be beginning in begining",
+				"a.go:7:34 \"recieved\" should be received in recieved",
+				"a.go:9:23 \"begining\" should be beginning in begining",
+			},
+		},
+		{name: "since HEAD grouped",
+			flags: Flags{Since: "HEAD", GroupByDeclaration: true},
+			want: []string{
+				"a.go:5:5 \"recieved\" should be received in recieved (1 reference)",
+				"a.go:7:23 \"begining\" should be beginning in begining (1 reference)",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings, err := FindIdentifierTypos([]string{"a.go"}, tt.flags)
+			if err != nil {
+				t.Fatalf("FindIdentifierTypos %v", err)
+			}
+
+			var got []string
+			for _, f := range findings {
+				got = append(got, f.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("\ngot %q\nexp %q\n", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := FindIdentifierTypos([]string{"a.go"}, Flags{Since: "no-such-revision"}); err == nil {
+		t.Fatalf("expected error for unknown revision")
+	}
+}
+
+func Test_FindIdentifierTyposDiffFromSubdirectory(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+
+	dir, write := fixture(t, map[string]string{
+		"go.mod":   "module example.com/fixture\n",
+		"pkg/a.go": "package pkg\n\nvar begining int\n",
+	})
+
+	runGit := func(args ...string) string {
+		t.Helper()
+		args = append([]string{"-c", "user.name=identypo", "-c", "user.email=identypo@example.com"}, args...)
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("git %v: %v", args, err)
+		}
+		return string(out)
+	}
+	runGit("init", "-q")
+	runGit("add", ".")
+	runGit("commit", "-q", "-m", "initial")
+
+	// the diff names pkg/a.go from the root of the repository, while identypo is run from pkg
+	write("pkg/a.go", "package pkg\n\nvar begining int\n\nvar recieved int\n")
+	write("changes.diff", runGit("diff", "HEAD"))
+
+	defer inModule(t, filepath.Join(dir, "pkg"))()
+
+	findings, err := FindIdentifierTypos([]string{"a.go"}, Flags{Diff: filepath.Join(dir, "changes.diff")})
+	if err != nil {
+		t.Fatalf("FindIdentifierTypos %v", err)
+	}
+
+	var got []string
+	for _, f := range findings {
+		got = append(got, f.String())
+	}
+	want := []string{"a.go:5:5 \"recieved\" should be received in recieved"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("\ngot %q\nexp %q\n", got, want)
+	}
+}
@@ -0,0 +1,197 @@
+package identypo
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+// Severities of findings, as reported in Finding.Severity. Misspellings in the public API of a package are the
+// expensive ones, since fixing them is a breaking change for its users.
+const (
+	SeverityPublic   = "public"   // exported package-level declarations, methods and struct fields, and their uses
+	SeverityInternal = "internal" // every other identifier
+)
+
+// publicMarker marks findings in the public API in text output and analyzer diagnostics.
+const publicMarker = "[public API]"
+
+// publicDeclarations returns the identifiers declaring the public API of f, named filename: its exported package-level
+// declarations, the exported methods of its exported types, and the exported fields and interface methods of its
+// exported types. Commands (package main) and test files can't be imported, so they have no public API.
+func publicDeclarations(filename string, f *ast.File) []*ast.Ident {
+	if f.Name.Name == "main" || strings.HasSuffix(filename, "_test.go") {
+		return nil
+	}
+
+	var public []*ast.Ident
+
+	// members adds the exported fields and methods of a struct or interface type, including those of nested
+	// struct types, as in type Config struct { Server struct { Adress string } }
+	var members func(expr ast.Expr)
+	members = func(expr ast.Expr) {
+		var list *ast.FieldList
+		switch t := expr.(type) {
+		case *ast.StarExpr:
+			members(t.X)
+			return
+		case *ast.StructType:
+			list = t.Fields
+		case *ast.InterfaceType:
+			list = t.Methods
+		default:
+			return
+		}
+
+		for _, field := range list.List {
+			for _, name := range field.Names {
+				if name.IsExported() {
+					public = append(public, name)
+				}
+			}
+			members(field.Type)
+		}
+	}
+
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if !d.Name.IsExported() {
+				continue
+			}
+			if d.Recv == nil || len(d.Recv.List) == 0 {
+				public = append(public, d.Name)
+				continue
+			}
+			if recv := receiverType(d.Recv.List[0].Type); recv != nil && recv.IsExported() {
+				public = append(public, d.Name)
+			}
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.ValueSpec:
+					for _, name := range s.Names {
+						if name.IsExported() {
+							public = append(public, name)
+						}
+					}
+				case *ast.TypeSpec:
+					if s.Name.IsExported() {
+						public = append(public, s.Name)
+						members(s.Type)
+					}
+				}
+			}
+		}
+	}
+
+	return public
+}
+
+// receiverType returns the name of the type of a method receiver, or nil if it isn't a named type.
+func receiverType(expr ast.Expr) *ast.Ident {
+	for {
+		switch t := expr.(type) {
+		case *ast.StarExpr:
+			expr = t.X
+		case *ast.ParenExpr:
+			expr = t.X
+		case *ast.IndexExpr:
+			expr = t.X
+		case *ast.IndexListExpr:
+			expr = t.X
+		case *ast.Ident:
+			return t
+		default:
+			return nil
+		}
+	}
+}
+
+// severity returns the severity of a misspelling in ident: public if it declares, or refers to, part of the public
+// API of a package, and internal otherwise.
+func (c *checker) severity(fset *token.FileSet, ident *ast.Ident) string {
+	if c.publicAPI(fset, ident) {
+		return SeverityPublic
+	}
+	return SeverityInternal
+}
+
+// publicAPI reports whether ident declares, or refers to, part of the public API of a package. Declarations in the
+// files walked by checkFiles are classified by their syntax, while the objects of other packages (such as dependencies)
+// are classified using type information if it's available.
+func (c *checker) publicAPI(fset *token.FileSet, ident *ast.Ident) bool {
+	pos := c.declarationPos(fset, ident)
+	if !pos.IsValid() {
+		return false
+	}
+	if c.walkedFiles[pos.Filename] {
+		return c.public[pos]
+	}
+
+	if c.info == nil {
+		return false
+	}
+	obj := c.info.Uses[ident]
+	return obj != nil && publicObject(obj)
+}
+
+// declaredPublic reports whether ident declares, or refers to, part of the public API of the files walked by checkFiles.
+func (c *checker) declaredPublic(fset *token.FileSet, ident *ast.Ident) bool {
+	pos := c.declarationPos(fset, ident)
+	return pos.IsValid() && c.walkedFiles[pos.Filename] && c.public[pos]
+}
+
+// declarationPos returns the position of the declaration ident refers to, or of ident itself if it's a declaration
+// the parser didn't resolve (such as a method without type information), or an invalid position.
+func (c *checker) declarationPos(fset *token.FileSet, ident *ast.Ident) token.Position {
+	if pos := c.objectPos(fset, ident); pos.IsValid() {
+		return pos
+	}
+	if _, ok := c.declKinds[ident]; ok {
+		return fset.Position(ident.Pos())
+	}
+	return token.Position{}
+}
+
+// publicObject reports whether obj is part of the public API of its package. Exported fields are assumed to belong
+// to an exported type, since the struct declaring a field can't be found from its object.
+func publicObject(obj types.Object) bool {
+	if !obj.Exported() || obj.Pkg() == nil || obj.Pkg().Name() == "main" {
+		return false
+	}
+
+	switch obj := obj.(type) {
+	case *types.Func:
+		sig, ok := obj.Type().(*types.Signature)
+		if !ok || sig.Recv() == nil {
+			break
+		}
+		recv := sig.Recv().Type()
+		if ptr, ok := recv.(*types.Pointer); ok {
+			recv = ptr.Elem()
+		}
+		if named, ok := recv.(*types.Named); ok {
+			return named.Obj().Exported()
+		}
+		// the methods of interfaces that aren't named, such as embedded interface literals
+		return true
+	case *types.Var:
+		if obj.IsField() {
+			return true
+		}
+	}
+
+	return obj.Parent() == obj.Pkg().Scope()
+}
+
+// recordWalk stores the declarations found by v, which walked the files being checked, in the checker.
+func (c *checker) recordWalk(fset *token.FileSet, v *returnsVisitor) {
+	c.declKinds = v.kinds
+	c.walkedFiles = v.files
+	c.public = make(map[token.Position]bool, len(v.public))
+	for _, ident := range v.public {
+		c.public[fset.Position(ident.Pos())] = true
+	}
+}
@@ -0,0 +1,171 @@
+package identypo
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"testing"
+)
+
+// exportedSrc has misspellings in its public API, and in exported names that aren't part of it.
+const exportedSrc = `package api
+
+type Reciever struct {
+	Adress  string
+	Server  struct{ Tennant string }
+	occured bool
+}
+
+func (r *Reciever) Recieve() {}
+
+type Paramaters interface {
+	Recieved() bool
+}
+
+type succesful struct {
+	Begining int
+}
+
+func (s succesful) Recieve() {}
+
+func Propogate() *Reciever {
+	Recieved := &Reciever{}
+	Recieved.Recieve()
+	return Recieved
+}
+
+const Inital = 1
+`
+
+func Test_FindIdentifierTyposExportedOnly(t *testing.T) {
+	dir, _ := fixture(t, map[string]string{
+		"go.mod": "module example.com/api\n",
+		"api.go": exportedSrc,
+	})
+	defer inModule(t, dir)()
+
+	corrections := map[string]string{"tennant": "tenant", "paramaters": "parameters"}
+
+	tests := []struct {
+		name  string
+		flags Flags
+		want  []string
+	}{
+		{name: "every identifier",
+			flags: Flags{Corrections: corrections, GroupByDeclaration: true},
+			want: []string{
+				"api.go:3:6 \"Reciever\" should be Receiver in Reciever (3 references) [public API]",
+				"api.go:4:2 \"Adress\" should be Address in Adress [public API]",
+				"api.go:5:18 \"Tennant\" should be Tenant in Tennant [public API]",
+				"api.go:6:2 \"occured\" should be occurred in occured",
+				"api.go:9:20 \"Recieve\" should be Receive in Recieve (1 reference) [public API]",
+				"api.go:11:6 \"Paramaters\" should be Parameters in Paramaters [public API]",
+				"api.go:12:2 \"Recieved\" should be Received in Recieved [public API]",
+				"api.go:15:6 \"succesful\" should be successful in succesful (1 reference)",
+				"api.go:16:2 \"Begining\" should be Beginning in Begining",
+				"api.go:19:20 \"Recieve\" should be Receive in Recieve",
+				"api.go:21:6 \"Propogate\" should be Propagate in Propogate [public API]",
+				"api.go:22:2 \"Recieved\" should be Received in Recieved (2 references)",
+				"api.go:27:7 \"Inital\" should be Initial in Inital [public API]",
+			},
+		},
+		{name: "exported only",
+			flags: Flags{Corrections: corrections, GroupByDeclaration: true, ExportedOnly: true},
+			want: []string{
+				"api.go:3:6 \"Reciever\" should be Receiver in Reciever (3 references) [public API]",
+				"api.go:4:2 \"Adress\" should be Address in Adress [public API]",
+				"api.go:5:18 \"Tennant\" should be Tenant in Tennant [public API]",
+				"api.go:9:20 \"Recieve\" should be Receive in Recieve (1 reference) [public API]",
+				"api.go:11:6 \"Paramaters\" should be Parameters in Paramaters [public API]",
+				"api.go:12:2 \"Recieved\" should be Received in Recieved [public API]",
+				"api.go:21:6 \"Propogate\" should be Propagate in Propogate [public API]",
+				"api.go:27:7 \"Inital\" should be Initial in Inital [public API]",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings, err := FindIdentifierTypos([]string{"."}, tt.flags)
+			if err != nil {
+				t.Fatalf("FindIdentifierTypos %v", err)
+			}
+
+			var got []string
+			for _, f := range findings {
+				got = append(got, f.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("\ngot %q\nexp %q\n", got, tt.want)
+			}
+
+			// the public API is found the same way without type information, although uses of methods
+			// and fields can't be resolved (so they aren't counted as references)
+			fset := token.NewFileSet()
+			f, err := parser.ParseFile(fset, "api.go", exportedSrc, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			flags, err := tt.flags.prepare()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var untyped []string
+			for _, finding := range findTypos(fset, []*ast.File{f}, nil, flags) {
+				if finding.Declaration {
+					finding.References = nil
+					untyped = append(untyped, finding.String())
+				}
+			}
+			var declarations []string
+			for _, f := range findings {
+				if f.Declaration {
+					f.References = nil
+					declarations = append(declarations, f.String())
+				}
+			}
+			if !reflect.DeepEqual(untyped, declarations) {
+				t.Fatalf("without type information\ngot %q\nexp %q\n", untyped, declarations)
+			}
+		})
+	}
+}
+
+func Test_publicDeclarations(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		src      string
+		want     []string
+	}{
+		{name: "library",
+			filename: "api.go",
+			src:      "package api\n\nfunc Propogate() {}\n\nfunc inital() {}\n",
+			want:     []string{"Propogate"},
+		},
+		{name: "command",
+			filename: "main.go",
+			src:      "package main\n\nfunc Propogate() {}\n\nfunc main() {}\n",
+		},
+		{name: "test file",
+			filename: "api_test.go",
+			src:      "package api\n\nfunc TestPropogate() {}\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := parser.ParseFile(token.NewFileSet(), tt.filename, tt.src, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, ident := range publicDeclarations(tt.filename, f) {
+				got = append(got, ident.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("\ngot %q\nexp %q\n", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,425 @@
+package identypo
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Rename describes a misspelled declaration found by FixIdentifierTypos.
+// * Filename, Line - position of the declaration.
+// * From, To - the misspelled name and the corrected name.
+// * References - the number of identifiers (including the declaration) that were renamed.
+// * Err - the reason the rename was refused (for example, To collides with an existing name), or nil if it was applied.
+type Rename struct {
+	Filename   string
+	Line       int
+	From, To   string
+	References int
+	Err        error
+}
+
+// String formats the rename the same way the identypo command line tool reports it.
+func (r Rename) String() string {
+	if r.Err != nil {
+		return fmt.Sprintf("%v:%v not renaming %v to %v: %v", r.Filename, r.Line, r.From, r.To, r.Err)
+	}
+	return fmt.Sprintf("%v:%v renamed %v to %v (%v)", r.Filename, r.Line, r.From, r.To, references(r.References))
+}
+
+// FixIdentifierTypos takes the same arguments as CheckForIdentiferTypos, and renames every misspelled declaration
+// (along with every reference to it in the loaded packages) to its corrected name, writing the changed files in place.
+// Type information is used to find references, so references in packages that were not loaded are not renamed.
+// A rename is refused if the corrected name would collide with another object in scope, or if a method would stop
+// matching an interface method it implements (or is implemented by) that isn't renamed with it. With flags.Diff or
+// flags.Since, only declarations on added lines are renamed.
+func FixIdentifierTypos(args []string, flags Flags) ([]Rename, error) {
+
+	// inconsistent spellings are reported, but not renamed, and every file is checked for typos to rename
+	flags.Consistency = false
+	flags.Cache = ""
+
+	flags, err := flags.prepare()
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+
+	// test files are always loaded so references in them are renamed too, but declarations in tests
+	// are only renamed if flags.IncludeTests is set
+	files, info, err := parseInput(args, fset, true, flags.workers())
+	if err != nil {
+		return nil, fmt.Errorf("could not parse input %v", err)
+	}
+
+	checked := files
+	if !flags.IncludeTests {
+		checked = make([]*ast.File, len(files))
+		for i, f := range files {
+			if !strings.HasSuffix(fset.File(f.Pos()).Name(), "_test.go") {
+				checked[i] = f
+			}
+		}
+	}
+
+	renames, edits := planRenames(fset, files, checked, info, flags)
+
+	if err := applyEdits(edits); err != nil {
+		return renames, err
+	}
+
+	return renames, nil
+}
+
+// edit replaces the identifier at offset in a file with a new name.
+type edit struct {
+	offset int
+	length int
+	name   string
+}
+
+// planRenames finds misspelled declarations in checked and works out the edits needed to rename them
+// (and their references in files), keyed by file name.
+func planRenames(fset *token.FileSet, files, checked []*ast.File, info *types.Info, flags Flags) ([]Rename, map[string][]edit) {
+	c := newChecker(flags)
+	c.info = info
+
+	// collect every declared object in checked with a misspelling, in the order they were declared.
+	// Objects are keyed by the position of their declaration, since a package and its test variant
+	// (or a package and its importers) may each have their own object for the same declaration.
+	var objs []types.Object
+	declared := make(map[token.Position]*ast.Ident)
+
+	// misspellings are found the same way as when checking, so suppressed declarations and excluded files are
+	// left alone
+	_, idents := c.checkFiles(fset, checked)
+	for _, ident := range idents {
+		obj := info.Defs[ident]
+		if obj == nil || declared[fset.Position(obj.Pos())] != nil {
+			continue
+		}
+		declared[fset.Position(obj.Pos())] = ident
+		objs = append(objs, obj)
+	}
+
+	// collect the references to each of those objects in all files
+	refs := make(map[token.Position][]*ast.Ident)
+	retVis := &returnsVisitor{
+		f: fset,
+	}
+	for _, f := range files {
+		ast.Walk(retVis, f)
+	}
+	for _, ident := range retVis.identifiers {
+		if obj := info.Uses[ident]; obj != nil && declared[fset.Position(obj.Pos())] != nil {
+			key := fset.Position(obj.Pos())
+			refs[key] = append(refs[key], ident)
+		}
+	}
+
+	renames := make([]Rename, len(objs))
+	renamed := make(map[token.Position]int, len(objs))
+	for i, obj := range objs {
+		key := fset.Position(obj.Pos())
+		pos := fset.Position(declared[key].Pos())
+		r := Rename{
+			Filename: pos.Filename,
+			Line:     pos.Line,
+			From:     obj.Name(),
+		}
+
+		r.To, r.Err = c.correct(obj.Name())
+		if r.Err == nil {
+			r.Err = checkRename(fset, files, info, obj, refs[key], r.To)
+		}
+
+		renames[i] = r
+		renamed[key] = i
+	}
+
+	// a method can only be renamed along with the methods of the interfaces it implements (and the methods
+	// implementing it, for an interface method), so refusing one rename may refuse others in turn
+	links := make([][]implementation, len(objs))
+	for i, obj := range objs {
+		if m, ok := obj.(*types.Func); ok && m.Type().(*types.Signature).Recv() != nil {
+			links[i] = implementations(info, m)
+		}
+	}
+	for refused := true; refused; {
+		refused = false
+		for i, r := range renames {
+			if r.Err != nil {
+				continue
+			}
+			for _, link := range links[i] {
+				j, ok := renamed[fset.Position(link.method.Pos())]
+				if !ok || renames[j].Err != nil || renames[j].To != r.To {
+					renames[i].Err = link.err
+					refused = true
+					break
+				}
+			}
+		}
+	}
+
+	edits := make(map[string][]edit)
+	for i, obj := range objs {
+		if renames[i].Err != nil {
+			continue
+		}
+		key := fset.Position(obj.Pos())
+		for _, ident := range append([]*ast.Ident{declared[key]}, refs[key]...) {
+			p := fset.Position(ident.Pos())
+			edits[p.Filename] = append(edits[p.Filename], edit{offset: p.Offset, length: len(ident.Name), name: renames[i].To})
+			renames[i].References++
+		}
+	}
+
+	return renames, edits
+}
+
+// implementation is a method that has to be renamed along with another, and the error to report if it isn't.
+type implementation struct {
+	method types.Object
+	err    error
+}
+
+// implementations returns the methods that have to be renamed along with method so every type in info keeps
+// implementing the same interfaces: the interface methods it implements, or, for an interface method, the methods
+// implementing it.
+func implementations(info *types.Info, method *types.Func) []implementation {
+	// types are collected by their string, so they're compared in the same order every time
+	qualifier := types.RelativeTo(method.Pkg())
+	typesByName := make(map[string]types.Type)
+	for _, obj := range info.Defs {
+		if tn, ok := obj.(*types.TypeName); ok && !tn.IsAlias() {
+			if named, ok := tn.Type().(*types.Named); !ok || named.TypeParams().Len() == 0 {
+				typesByName[types.TypeString(tn.Type(), qualifier)] = tn.Type()
+			}
+		}
+	}
+	for _, tv := range info.Types {
+		if _, ok := tv.Type.Underlying().(*types.Interface); ok {
+			typesByName[types.TypeString(tv.Type, qualifier)] = tv.Type
+		}
+	}
+	names := make([]string, 0, len(typesByName))
+	for name := range typesByName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var links []implementation
+	for _, ifaceName := range names {
+		iface, ok := typesByName[ifaceName].Underlying().(*types.Interface)
+		if !ok {
+			continue
+		}
+		want, _, _ := types.LookupFieldOrMethod(iface, false, method.Pkg(), method.Name())
+		if want == nil {
+			continue
+		}
+
+		for _, name := range names {
+			t := typesByName[name]
+			if _, ok := t.Underlying().(*types.Interface); !ok {
+				// methods may be declared on the pointer type
+				t = types.NewPointer(t)
+			}
+			if !types.Implements(t, iface) {
+				continue
+			}
+			got, _, _ := types.LookupFieldOrMethod(t, false, method.Pkg(), method.Name())
+			if got == nil || got == want {
+				continue
+			}
+
+			err := fmt.Errorf("%v would no longer implement %v", name, ifaceName)
+			if got == method {
+				links = append(links, implementation{method: want, err: err})
+			} else if want == method {
+				links = append(links, implementation{method: got, err: err})
+			}
+		}
+	}
+
+	return links
+}
+
+// correct returns name with every misspelled word replaced by its correction. Corrections are
+// recombined in camelCase (or snake_case), the same way they are reported. An error is returned, along with
+// the name corrected anyway, if a misspelled word has alternatives, since the right one can't be picked for it.
+func (c *checker) correct(name string) (string, error) {
+	r := strings.Builder{}
+
+	var err error
+	end := 0
+	for _, m := range c.misspellings("", name) {
+		if len(m.alternatives) > 0 && err == nil {
+			err = fmt.Errorf("%v could also be %v", m.text, strings.Join(m.alternatives, " or "))
+		}
+		// keep the rest of the name (the correct words, and underscores between words) as is
+		r.WriteString(name[end:m.offset])
+		r.WriteString(joinCorrection(name, m.correction))
+		end = m.offset + len(m.text)
+	}
+	r.WriteString(name[end:])
+
+	return r.String(), err
+}
+
+// checkRename returns an error if renaming obj (referred to by refs) to name could change the meaning of the program.
+func checkRename(fset *token.FileSet, files []*ast.File, info *types.Info, obj types.Object, refs []*ast.Ident, name string) error {
+	if !token.IsIdentifier(name) || name == obj.Name() {
+		return fmt.Errorf("%q is not a valid replacement", name)
+	}
+
+	if obj.Pkg() != nil && token.IsExported(obj.Name()) != token.IsExported(name) {
+		return fmt.Errorf("renaming would change whether %v is exported", obj.Name())
+	}
+
+	collision := func(other types.Object) error {
+		return fmt.Errorf("%v collides with %v declared at %v", name, other.Name(), fset.Position(other.Pos()))
+	}
+
+	switch obj := obj.(type) {
+	case *types.Label:
+		// labels are scoped to the function they're declared in
+		for ident, other := range info.Defs {
+			if l, ok := other.(*types.Label); ok && l.Name() == name && enclosingDecl(files, ident.Pos()) == enclosingDecl(files, obj.Pos()) {
+				return collision(l)
+			}
+		}
+		return nil
+
+	case *types.Func:
+		if recv := obj.Type().(*types.Signature).Recv(); recv != nil {
+			// methods collide with other fields and methods of their receiver
+			if other, _, _ := types.LookupFieldOrMethod(recv.Type(), true, obj.Pkg(), name); other != nil {
+				return collision(other)
+			}
+			return nil
+		}
+
+	case *types.Var:
+		if obj.IsField() {
+			// fields collide with other fields and methods of the named struct declaring them
+			owner := fieldOwner(obj)
+			if owner == nil {
+				return fmt.Errorf("could not find the struct type declaring %v", obj.Name())
+			}
+			if other, _, _ := types.LookupFieldOrMethod(owner, true, obj.Pkg(), name); other != nil {
+				return collision(other)
+			}
+			return nil
+		}
+	}
+
+	// anything else collides with names visible from its declaration or from any of its unqualified references
+	if obj.Parent() == nil {
+		return fmt.Errorf("could not find the scope declaring %v", obj.Name())
+	}
+	if other := obj.Parent().Lookup(name); other != nil {
+		return collision(other)
+	}
+	if _, other := obj.Parent().LookupParent(name, obj.Pos()); other != nil {
+		return collision(other)
+	}
+	if obj.Parent() == obj.Pkg().Scope() {
+		// a package level name may also collide with an import in any of the package's files
+		for i := 0; i < obj.Parent().NumChildren(); i++ {
+			if other := obj.Parent().Child(i).Lookup(name); other != nil {
+				return collision(other)
+			}
+		}
+	}
+	for _, ref := range refs {
+		// references from other packages are qualified, so they can't be shadowed
+		scope := obj.Pkg().Scope().Innermost(ref.Pos())
+		if scope == nil {
+			continue
+		}
+		if _, other := scope.LookupParent(name, ref.Pos()); other != nil {
+			return collision(other)
+		}
+	}
+
+	return nil
+}
+
+// fieldOwner returns the named type whose underlying struct declares field, or nil if it can't be found.
+func fieldOwner(field *types.Var) types.Type {
+	if field.Pkg() == nil {
+		return nil
+	}
+
+	scope := field.Pkg().Scope()
+	for _, name := range scope.Names() {
+		tn, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok {
+			continue
+		}
+		if s, ok := tn.Type().Underlying().(*types.Struct); ok {
+			for i := 0; i < s.NumFields(); i++ {
+				if s.Field(i) == field {
+					return tn.Type()
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// enclosingDecl returns the top level declaration containing pos, or nil.
+func enclosingDecl(files []*ast.File, pos token.Pos) ast.Decl {
+	for _, f := range files {
+		if f == nil || pos < f.Pos() || pos > f.End() {
+			continue
+		}
+		for _, decl := range f.Decls {
+			if decl.Pos() <= pos && pos < decl.End() {
+				return decl
+			}
+		}
+	}
+	return nil
+}
+
+// applyEdits rewrites each file with its edits applied.
+func applyEdits(edits map[string][]edit) error {
+	for filename, fileEdits := range edits {
+		src, err := os.ReadFile(filename)
+		if err != nil {
+			return err
+		}
+
+		fi, err := os.Stat(filename)
+		if err != nil {
+			return err
+		}
+
+		// apply edits from the end of the file so earlier offsets stay valid, skipping duplicates
+		// (an embedded field is both a declaration of the field and a reference to its type)
+		sort.Slice(fileEdits, func(i, j int) bool { return fileEdits[i].offset > fileEdits[j].offset })
+		last := -1
+		for _, e := range fileEdits {
+			if e.offset == last {
+				continue
+			}
+			last = e.offset
+			src = append(src[:e.offset], append([]byte(e.name), src[e.offset+e.length:]...)...)
+		}
+
+		if err := os.WriteFile(filename, src, fi.Mode()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
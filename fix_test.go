@@ -0,0 +1,142 @@
+package identypo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_FixIdentifierTypos(t *testing.T) {
+	dir, renames := fixFixture(t, Flags{IncludeTests: true})
+
+	wantRenames := []string{
+		"a.go:4 renamed Propogate to Propagate (3 references)",
+		"a.go:4 renamed inital to initial (2 references)",
+		"a.go:9 renamed Reciever to Receiver (4 references)",
+		"a.go:10 renamed Adress to Address (3 references)",
+		"a.go:13 renamed Recieve to Receive (2 references)",
+		"b.go:10 not renaming begining to beginning: beginning collides with beginning declared at b.go:11:5",
+		"c.go:5 renamed Recieve to Receive (1 reference)",
+		"e.go:6 renamed Begining to Beginning (1 reference)",
+		"e.go:11 renamed Begining to Beginning (2 references)",
+		"b_test.go:5 renamed TestPropogate to TestPropagate (1 reference)",
+	}
+	if len(renames) != len(wantRenames) {
+		t.Fatalf("\ngot %v\nexp %v\n", renames, wantRenames)
+	}
+	for i := range wantRenames {
+		if renames[i].String() != wantRenames[i] {
+			t.Errorf("\ngot %v\nexp %v\n", renames[i], wantRenames[i])
+		}
+	}
+
+	want, err := filepath.Abs(filepath.Join("testdata", "fix", "want"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"a.go", "b.go", "b_test.go", "c.go", "d.go", "e.go"} {
+		got, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		exp, err := os.ReadFile(filepath.Join(want, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != string(exp) {
+			t.Errorf("%v\ngot %v\nexp %v\n", name, string(got), string(exp))
+		}
+	}
+}
+
+func Test_FixIdentifierTyposInterfaces(t *testing.T) {
+	tests := []struct {
+		name        string
+		flags       Flags
+		wantRenames []string
+	}{
+		// only methods are checked, so they can't be renamed without the interface methods they implement
+		{name: "methods only",
+			flags: Flags{Kinds: []string{KindMethod}},
+			wantRenames: []string{
+				"a.go:13 not renaming Recieve to Receive: Reciever would no longer implement Source",
+				"e.go:6 not renaming Begining to Beginning: Sequence would no longer implement interface{Begining() int}",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, renames := fixFixture(t, tt.flags)
+
+			if len(renames) != len(tt.wantRenames) {
+				t.Fatalf("\ngot %v\nexp %v\n", renames, tt.wantRenames)
+			}
+			for i := range tt.wantRenames {
+				if renames[i].String() != tt.wantRenames[i] {
+					t.Errorf("\ngot %v\nexp %v\n", renames[i], tt.wantRenames[i])
+				}
+			}
+
+			for _, name := range []string{"a.go", "c.go", "e.go"} {
+				got, err := os.ReadFile(filepath.Join(dir, name))
+				if err != nil {
+					t.Fatal(err)
+				}
+				exp, err := os.ReadFile(filepath.Join("testdata", "fix", "input", name))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(got) != string(exp) {
+					t.Errorf("%v\ngot %v\nexp %v\n", name, string(got), string(exp))
+				}
+			}
+		})
+	}
+}
+
+func Test_FixIdentifierTyposExcluded(t *testing.T) {
+	// declarations in a.go are left alone, so the interface method they implement can't be renamed either
+	_, renames := fixFixture(t, Flags{Exclude: []string{"a.go"}})
+
+	wantRenames := []string{
+		"b.go:10 not renaming begining to beginning: beginning collides with beginning declared at b.go:11:5",
+		"c.go:5 not renaming Recieve to Receive: Reciever would no longer implement Source",
+		"e.go:6 renamed Begining to Beginning (1 reference)",
+		"e.go:11 renamed Begining to Beginning (2 references)",
+	}
+	if len(renames) != len(wantRenames) {
+		t.Fatalf("\ngot %v\nexp %v\n", renames, wantRenames)
+	}
+	for i := range wantRenames {
+		if renames[i].String() != wantRenames[i] {
+			t.Errorf("\ngot %v\nexp %v\n", renames[i], wantRenames[i])
+		}
+	}
+}
+
+// fixFixture runs FixIdentifierTypos with flags on a copy of the fixture module (since fixing rewrites files in
+// place), returning the directory of the copy and the renames.
+func fixFixture(t *testing.T, flags Flags) (string, []Rename) {
+	inputs, err := filepath.Glob(filepath.Join("testdata", "fix", "input", "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string]string, len(inputs))
+	for _, input := range inputs {
+		src, err := os.ReadFile(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[filepath.Base(input)] = string(src)
+	}
+	dir, _ := fixture(t, files)
+
+	defer inModule(t, dir)()
+
+	renames, err := FixIdentifierTypos([]string{"./..."}, flags)
+	if err != nil {
+		t.Fatalf("FixIdentifierTypos %v", err)
+	}
+	return dir, renames
+}
@@ -1,46 +1,230 @@
 package identypo
 
 import (
+	"errors"
 	"fmt"
 	"go/ast"
 	"go/token"
+	"go/types"
 	"log"
 	"os"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/client9/misspell"
-	"github.com/fatih/camelcase"
 )
 
 // Flags contains configuration specific to identypo.
 // * Ignores - comma separated list of corrections to be ignored (for example, to stop corrections on "nto" and "creater", pass `-i="nto,creater"). This is a direct passthrough to the misspell package.
 // * IncludeTests - include test files in analysis
-// * FunctionsOnly - Find typos in function declarations only.
-// * ConstantsOnly - Find typos in constants only.
-// * VariablesOnly - Find typos in variables only.
-// * SetExitStatus - Set exit status to 1 if any issues are found.
-// Note: If FunctionsOnly, ConstantsOnly, and VariablesOnly are all false, every identifier will be searched for typos.
-// (functions, function calls, variables, constants, type declarations, packages, labels).
+// * Kinds - the kinds of identifiers to find typos in (see Kinds), for example []string{KindType, KindField} to check
+// only type declarations, struct fields and their uses. Every identifier is checked if empty.
+// * DeclarationsOnly - Find typos only in identifiers declared in the analyzed code (the definitions in types.Info.Defs),
+// ignoring uses, so names merely referenced from other packages (such as a dependency's GetInstanceStatuss) aren't reported.
+// * ExportedOnly - Find typos only in the public API of the analyzed code: exported package-level declarations, the
+// exported methods of exported types and the exported fields (and interface methods) of exported types, along with their uses.
+// * SetExitStatus - Report ErrIssuesFound from CheckForIdentiferTypos if any issues are found (the identypo command sets its exit status to 1 in this case).
+// * GroupByDeclaration - Report each misspelled object once (at its declaration, if it was analyzed) with its uses listed as references, rather than reporting every use.
+// * Corrections - additional corrections, keyed by misspelling (for example, "tennant": "tenant"). These are added to misspell's rules.
+// * Dictionaries - dictionary files of additional corrections (see ParseDictionary for the format). Corrections takes precedence over these.
+// * Include, Exclude - path globs restricting the files that are checked. A glob matches a file if it matches the path or base name of the file or any parent directory.
+// If Include is set, only matching files are checked. Files matching Exclude are never checked.
+// * GlobDir - the directory paths in Include and Exclude are relative to (the directory of the configuration file they're
+// from), the working directory if empty.
+// * Acronyms - acronyms recognized when splitting identifiers into words, in addition to Initialisms, for example
+// []string{"gRPC", "IPv6"}. See splitIdentifier.
+// * Segment - also find typos in words that run together in lower case, such as the recieved of recieveddata (see
+// segmenter), which misspell only finds at the start of a word.
+// * SegmentConfidence - the confidence (between 0 and 1) a split of run together words needs for its typos to be
+// reported, DefaultSegmentConfidence if 0. Higher values report fewer typos, with fewer false positives.
+// * WordLists - word list files (see LoadWordList), such as /usr/share/dict/words. Words that misspell doesn't know
+// to be misspelled, but that aren't in a word list and are close to a word that is (such as Procesor), are reported
+// with the nearest known words as suggestions.
+// * EditDistance - the largest number of edits (1 or 2) a word can be from a known word to be reported with WordLists,
+// DefaultEditDistance if 0. Words shorter than 8 letters are only reported a single edit away.
+// * Consistency - also report words spelled more than one way across the checked identifiers, such as Cancelled and
+// Canceled or Initialise and Initialize (even though both are real words), or a known word and an unknown spelling of
+// it with a letter doubled (Unmarshaler and Unmarshaller). A spelling used less often than another is reported, with
+// the spelling used most often as its correction (see spellings). Not used by FixIdentifierTypos.
+// * Locale - enforce US ("US") or UK ("UK" or "GB") spellings, for example reporting "Colour" in DefaultColourScheme
+// with "US". By default, a neutral variety of English is used and either spelling is accepted.
+// * ReportUnusedSuppressions - Report //identypo:ignore and //nolint:identypo comments that did not suppress any typos.
+// * Workers - the number of files parsed, and identifiers checked, concurrently. Defaults to the number of CPUs if 0.
+// Output is the same whatever the number of workers.
+// * Cache - a file caching the misspelled words of each file between runs. Files that haven't changed since the
+// previous run (with the same dictionary) are checked without consulting the dictionary. Not used by FixIdentifierTypos.
+// * Diff - a unified diff file ("-" for stdin), only typos in identifiers on lines it adds are reported. File names in the
+// diff are relative to the root of the git repository containing the working directory (or the working directory outside
+// of one), and a leading "b/" is removed, as written by git diff.
+// * Since - a git revision, only typos in identifiers on lines added since that revision are reported (see Diff).
+// Note: If Kinds is empty, every identifier will be searched for typos.
+// (functions, function calls, methods, variables, constants, type declarations, fields, packages, labels, etc.).
 type Flags struct {
-	Ignores                                     string
-	IncludeTests                                bool
-	FunctionsOnly, ConstantsOnly, VariablesOnly bool
-	SetExitStatus                               bool
+	Ignores                  string
+	IncludeTests             bool
+	Kinds                    []string
+	DeclarationsOnly         bool
+	ExportedOnly             bool
+	SetExitStatus            bool
+	GroupByDeclaration       bool
+	Corrections              map[string]string
+	Dictionaries             []string
+	Acronyms                 []string
+	Segment                  bool
+	SegmentConfidence        float64
+	WordLists                []string
+	EditDistance             int
+	Consistency              bool
+	Locale                   string
+	Include, Exclude         []string
+	GlobDir                  string
+	ReportUnusedSuppressions bool
+	Diff, Since              string
+	Workers                  int
+	Cache                    string
+
+	// added holds the lines added by Diff or Since, cache the cache read from Cache, and words the words of
+	// WordLists, loaded by prepare
+	added addedLines
+	cache *fileCache
+	words []string
 }
 
+// ErrIssuesFound is returned by CheckForIdentiferTypos when Flags.SetExitStatus is set and at least one typo was found.
+var ErrIssuesFound = errors.New("identypo: issues found")
+
 // CheckForIdentiferTypos takes a slice of file arguments (this could be file names, directories, or packages (with or without the ... wildcard).
 // Further configuration (such as words to ignore, whether or not to include tests, etc.) can be specified with the flags argument. Output is written
 // using the log.Printf function. This is currently not configurable. For redirection to a file/buffer, see the log.SetOutput() method.
+// If flags.SetExitStatus is set and any typos were found, ErrIssuesFound is returned.
 func CheckForIdentiferTypos(args []string, flags Flags) error {
 
+	flags, err := flags.prepare()
+	if err != nil {
+		return err
+	}
+
 	fset := token.NewFileSet()
 
-	files, err := parseInput(args, fset, flags.IncludeTests)
+	files, info, err := parseInput(args, fset, flags.IncludeTests, flags.workers())
 	if err != nil {
 		return fmt.Errorf("could not parse input %v", err)
 	}
 
-	return processIdentifiers(fset, files, flags)
+	err = processIdentifiers(fset, files, info, flags)
+	if cacheErr := flags.cache.save(); cacheErr != nil {
+		return cacheErr
+	}
+	return err
+}
+
+// Finding describes a single misspelled word found within an identifier.
+// * Filename, Line, Column - position of the identifier containing the misspelling.
+// * Word - the misspelled word (see splitIdentifier), for example "Succesful".
+// * Offset, Length - the byte offset and length of Word within Identifier, for example 8 and 9 for "Succesful" in
+// "constantSuccesful". The misspelling starts at column Column+Offset.
+// * Correction - the suggested correction for Word, for example "Successful".
+// * Alternatives - other words Word may be a misspelling of, nearest first, when it was found with Flags.WordLists
+// and several known words are as close (for example "cable" and "table" for "lable", corrected to "label").
+// * Identifier - the full identifier the word was found in, for example "constantSuccesful".
+// * Kind - the kind of the identifier (func, method, var, field, etc., see Kinds), or of the identifier a use refers to.
+// Empty if it could not be resolved.
+// * Declaration - whether the identifier declares the misspelled name, as opposed to using (referring to) it.
+// * Severity - SeverityPublic if the identifier declares, or refers to, part of the public API of a package (so fixing
+// it is a breaking change), otherwise SeverityInternal.
+// * Inconsistent - whether Word isn't misspelled, but is spelled differently in most of the other identifiers checked
+// (see Flags.Consistency). Correction is then the spelling used most often.
+// * References - when grouping by declaration, the other identifiers referring to the same object.
+type Finding struct {
+	Filename     string     `json:"file"`
+	Line         int        `json:"line"`
+	Column       int        `json:"column"`
+	Word         string     `json:"word"`
+	Offset       int        `json:"offset"`
+	Length       int        `json:"length"`
+	Correction   string     `json:"suggestion"`
+	Alternatives []string   `json:"alternatives,omitempty"`
+	Identifier   string     `json:"identifier"`
+	Kind         string     `json:"kind"`
+	Declaration  bool       `json:"declaration"`
+	Severity     string     `json:"severity"`
+	Inconsistent bool       `json:"inconsistent,omitempty"`
+	References   []Location `json:"references,omitempty"`
+}
+
+// Location is the position of an identifier in a file.
+type Location struct {
+	Filename string `json:"file"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+}
+
+// String formats the finding the same way the identypo command line tool reports it. The column reported is
+// that of the misspelled word, rather than of the identifier, so editors jump straight to the misspelling.
+// Findings in the public API are marked with "[public API]".
+func (f Finding) String() string {
+	if f.Kind == KindUnusedSuppression {
+		return fmt.Sprintf("%v:%v:%v unused suppression %v", f.Filename, f.Line, f.Column, f.Word)
+	}
+
+	s := fmt.Sprintf("%v:%v:%v %v", f.Filename, f.Line, f.Column+f.Offset, f.message())
+	if len(f.References) > 0 {
+		s += fmt.Sprintf(" (%v)", references(len(f.References)))
+	}
+
+	// typos in the public API are marked, since fixing them is a breaking change
+	if f.Severity == SeverityPublic {
+		s += " " + publicMarker
+	}
+	return s
+}
+
+// references returns the number of references n, as in "1 reference" or "3 references".
+func references(n int) string {
+	if n == 1 {
+		return "1 reference"
+	}
+	return fmt.Sprintf("%d references", n)
+}
+
+// FindIdentifierTypos is like CheckForIdentiferTypos, but returns the typos found as a slice of findings
+// instead of writing them with log.Printf.
+func FindIdentifierTypos(args []string, flags Flags) ([]Finding, error) {
+	findings, _, err := FindIdentifierTyposAndFiles(args, flags)
+	return findings, err
+}
+
+// FindIdentifierTyposAndFiles is like FindIdentifierTypos, but also returns the names of the files that were
+// checked (leaving out excluded files), so a baseline can tell fixed typos apart from typos in files that
+// weren't checked this run.
+func FindIdentifierTyposAndFiles(args []string, flags Flags) ([]Finding, []string, error) {
+
+	flags, err := flags.prepare()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fset := token.NewFileSet()
+
+	files, info, err := parseInput(args, fset, flags.IncludeTests, flags.workers())
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not parse input %v", err)
+	}
+
+	findings := findTypos(fset, files, info, flags)
+	if err := flags.cache.save(); err != nil {
+		return nil, nil, err
+	}
+
+	var checked []string
+	for _, f := range files {
+		if f == nil || flags.excluded(fset.File(f.Pos()).Name()) {
+			continue
+		}
+		checked = append(checked, fset.File(f.Pos()).Name())
+	}
+	return findings, checked, nil
 }
 
 // hyphenToCamelCase converts a hyphenated word into camelCase.
@@ -68,85 +252,622 @@ func hyphenToCamelCase(s string) string {
 	return r.String()
 }
 
-func processIdentifiers(fset *token.FileSet, files []*ast.File, flags Flags) error {
-	all := !flags.FunctionsOnly && !flags.ConstantsOnly && !flags.VariablesOnly
+func processIdentifiers(fset *token.FileSet, files []*ast.File, info *types.Info, flags Flags) error {
+	findings := findTypos(fset, files, info, flags)
 
-	retVis := &returnsVisitor{
-		f:        fset,
-		replacer: misspell.New(),
+	for _, f := range findings {
+		log.Printf("%v\n", f)
 	}
 
-	if len(flags.Ignores) > 0 {
-		lci := strings.ToLower(flags.Ignores)
-		retVis.replacer.RemoveRule(strings.Split(lci, ","))
+	if flags.SetExitStatus && len(findings) > 0 {
+		return ErrIssuesFound
+	}
+	return nil
+}
+
+// findTypos walks every identifier in files and returns a finding for each misspelled word, honoring
+// the ignores and identifier filters in flags. info may be nil if no type information is available.
+func findTypos(fset *token.FileSet, files []*ast.File, info *types.Info, flags Flags) []Finding {
+	c := newChecker(flags)
+	c.info = info
+
+	findings, idents := c.checkFiles(fset, files)
+
+	var grouped []Finding
+	for _, group := range c.group(fset, findings, idents) {
+		f := findings[group[0]]
+		for _, j := range group[1:] {
+			f.References = append(f.References, findings[j].location())
+		}
+		grouped = append(grouped, f)
+	}
+
+	if flags.ReportUnusedSuppressions {
+		for _, s := range c.unusedSuppressions() {
+			pos := fset.Position(s.pos)
+			if !c.added(pos) {
+				continue
+			}
+			grouped = append(grouped, Finding{
+				Filename: pos.Filename,
+				Line:     pos.Line,
+				Column:   pos.Column,
+				Word:     s.text,
+				Length:   len(s.text),
+				Kind:     KindUnusedSuppression,
+			})
+		}
 	}
 
-	retVis.replacer.Compile()
+	return grouped
+}
 
+// checkChunkSize is the number of identifiers checkFiles hands to a worker at a time.
+const checkChunkSize = 256
+
+// checkFiles walks every identifier in files and returns a finding for each misspelled word, along with
+// the identifier each finding was found in. Identifiers covered by a suppression comment are skipped, along with
+// every use of a declaration covered by one.
+func (c *checker) checkFiles(fset *token.FileSet, files []*ast.File) ([]Finding, []*ast.Ident) {
+	retVis := &returnsVisitor{
+		f: fset,
+	}
+
+	if c.flags.cache != nil {
+		c.loadCachedFiles(fset, files)
+	}
+
+	var sups []*suppression
 	for _, f := range files {
-		if f == nil {
+		if f == nil || c.flags.excluded(fset.File(f.Pos()).Name()) {
+			continue
+		}
+		// a file cached without any misspelled words has nothing to report (or suppress), though its words still
+		// count towards the spellings compared for consistency
+		if words, ok := c.cachedFiles[fset.File(f.Pos()).Name()]; ok && len(words) == 0 && !c.flags.ReportUnusedSuppressions && !c.flags.Consistency {
 			continue
 		}
 		ast.Walk(retVis, f)
+		sups = append(sups, suppressions(fset, f)...)
 	}
+	c.suppressions = append(c.suppressions, sups...)
+	c.recordWalk(fset, retVis)
+
+	// check identifiers concurrently in chunks, then handle the results in order so output is deterministic
+	checked := make([][]Finding, len(retVis.identifiers))
+	chunks := (len(retVis.identifiers) + checkChunkSize - 1) / checkChunkSize
+	parallel(chunks, c.flags.workers(), func(chunk int) {
+		for i := chunk * checkChunkSize; i < len(retVis.identifiers) && i < (chunk+1)*checkChunkSize; i++ {
+			checked[i] = c.check(fset, retVis.identifiers[i])
+		}
+	})
 
-	exitStatus := 0
-
-	for _, ident := range retVis.identifiers {
-		for _, word := range camelcase.Split(ident.Name) {
-			v, d := retVis.replacer.Replace(word)
-
-			// convert any hyphenated words into camelCase
-			v = hyphenToCamelCase(v)
-
-			if len(d) > 0 {
-				exitStatus = 1
-				file := retVis.f.File(ident.Pos())
-				fileName := file.Name()
-				line := file.Position(ident.Pos()).Line
-
-				if all {
-					// if we're including everything, no need to look at the kind of identifier we have
-					log.Printf("%v:%v %q should be %v in %v\n", fileName, line, word, v, ident.Name)
-				} else if ident.Obj != nil {
-					switch ident.Obj.Kind {
-					case ast.Fun:
-						if !flags.FunctionsOnly {
-							continue
-						}
-					case ast.Var:
-						if !flags.VariablesOnly {
-							continue
-						}
-					case ast.Con:
-						if !flags.ConstantsOnly {
-							continue
-						}
-					default:
-						// labels, packages, etc. currently do not have individual flags and will be skipped
-						continue
-					}
-					log.Printf("%v:%v %q should be %v in %v\n", fileName, line, word, v, ident.Name)
-				}
+	if c.flags.Consistency {
+		for i, inconsistent := range c.inconsistencies(fset, retVis.identifiers) {
+			if len(inconsistent) == 0 {
+				continue
 			}
+			// keep the findings of each identifier in the order of its words
+			checked[i] = append(checked[i], inconsistent...)
+			sort.SliceStable(checked[i], func(a, b int) bool { return checked[i][a].Offset < checked[i][b].Offset })
+		}
+	}
+
+	var findings []Finding
+	var idents []*ast.Ident
+	suppressedObjects := make(map[token.Position]bool)
+
+	for i, ident := range retVis.identifiers {
+		identFindings := checked[i]
+		if len(identFindings) == 0 {
+			continue
+		}
+
+		if s := suppressedBy(sups, ident.Pos()); s != nil {
+			s.used = true
+			if object := c.objectPos(fset, ident); object.IsValid() && c.isDeclaration(ident) {
+				suppressedObjects[object] = true
+			}
+			continue
+		}
+
+		if !c.added(fset.Position(ident.Pos())) {
+			continue
+		}
+
+		for _, f := range identFindings {
+			findings = append(findings, f)
+			idents = append(idents, ident)
 		}
 	}
 
-	if flags.SetExitStatus {
-		os.Exit(exitStatus)
+	if len(suppressedObjects) == 0 {
+		return findings, idents
 	}
-	return nil
+
+	// drop uses of suppressed declarations, wherever they are
+	var keptFindings []Finding
+	var keptIdents []*ast.Ident
+	for i, ident := range idents {
+		if suppressedObjects[c.objectPos(fset, ident)] {
+			continue
+		}
+		keptFindings = append(keptFindings, findings[i])
+		keptIdents = append(keptIdents, ident)
+	}
+
+	return keptFindings, keptIdents
+}
+
+// added reports whether pos is on a line added by the diff in the checker's flags, or true if there is no diff.
+func (c *checker) added(pos token.Position) bool {
+	if c.flags.added == nil {
+		return true
+	}
+
+	lines, ok := c.addedByFile[pos.Filename]
+	if !ok {
+		if c.addedByFile == nil {
+			c.addedByFile = make(map[string]map[int]bool)
+		}
+		lines = c.flags.added.file(pos.Filename)
+		c.addedByFile[pos.Filename] = lines
+	}
+	return lines[pos.Line]
+}
+
+// loadCachedFiles records the misspelled words of every file in files that was cached by a previous run,
+// and starts tracking the rest in the cache.
+func (c *checker) loadCachedFiles(fset *token.FileSet, files []*ast.File) {
+	var names []string
+	for _, f := range files {
+		if f != nil && !c.flags.excluded(fset.File(f.Pos()).Name()) {
+			names = append(names, fset.File(f.Pos()).Name())
+		}
+	}
+
+	cached := make([]map[string]string, len(names))
+	parallel(len(names), c.flags.workers(), func(i int) {
+		src, err := os.ReadFile(names[i])
+		if err != nil {
+			// files that can't be read are checked, but not cached
+			return
+		}
+		if words, ok := c.flags.cache.file(names[i], src); ok {
+			cached[i] = words
+		}
+	})
+
+	c.cachedFiles = make(map[string]map[string]string)
+	for i, words := range cached {
+		if words != nil {
+			c.cachedFiles[names[i]] = words
+		}
+	}
+}
+
+// segmenter returns the checker's segmenter, creating it first if this is the first use.
+func (c *checker) segmenter() *segmenter {
+	c.segmenterOnce.Do(func() {
+		// an invalid confidence has already been reported by prepare
+		confidence, _ := segmentConfidence(c.flags.SegmentConfidence)
+		c.segments = newSegmenter(c.replacer.Replacements, confidence)
+	})
+	return c.segments
+}
+
+// lexicon returns the checker's lexicon, creating it first if this is the first use, or nil without flags.WordLists.
+func (c *checker) lexicon() *lexicon {
+	c.lexiconOnce.Do(func() {
+		if len(c.flags.WordLists) == 0 {
+			return
+		}
+		// an invalid edit distance has already been reported by prepare
+		distance, _ := editDistance(c.flags.EditDistance)
+		c.known = newLexicon(c.flags.words, c.replacer.Replacements, c.acronyms, distance)
+	})
+	return c.known
+}
+
+// compiled returns the checker's replacer, compiling it first if this is the first use.
+func (c *checker) compiled() *misspell.Replacer {
+	c.compileOnce.Do(c.replacer.Compile)
+	return c.replacer
+}
+
+// replace returns the verdict for word, found in filename. Verdicts are looked up in the cache if filename
+// was cached by a previous run, and otherwise are only checked against the dictionary (and then the word lists
+// in flags.WordLists) once per run.
+func (c *checker) replace(filename, word string) verdict {
+	if words, ok := c.cachedFiles[filename]; ok {
+		suggestions, misspelled := words[word]
+		return cachedVerdict(suggestions, misspelled)
+	}
+
+	v, ok := c.words.get(word)
+	if !ok {
+		corrected, diffs := c.compiled().Replace(word)
+		v = verdict{correction: corrected, misspelled: len(diffs) > 0}
+		if l := c.lexicon(); !v.misspelled && l != nil {
+			if suggestions := l.suggest(word); len(suggestions) > 0 {
+				v = misspelledVerdict(suggestions)
+			}
+		}
+		c.words.put(word, v)
+	}
+
+	if v.misspelled && c.flags.cache != nil {
+		c.flags.cache.record(filename, word, v.suggestions())
+	}
+	return v
+}
+
+// unusedSuppressions returns the suppression comments seen by checkFiles that did not suppress anything.
+func (c *checker) unusedSuppressions() []*suppression {
+	var unused []*suppression
+	for _, s := range c.suppressions {
+		if !s.used {
+			unused = append(unused, s)
+		}
+	}
+	return unused
+}
+
+// groupKey identifies a misspelled word in the object declared at pos.
+type groupKey struct {
+	pos  token.Position
+	word string
 }
 
+// group returns the indices of findings to report. Each group holds the index of the finding to report followed by
+// the indices of its references. Unless grouping by declaration, every finding is reported on its own. Otherwise
+// findings for the same misspelled object are merged, reporting the declaration if it was found (or the first use
+// if it wasn't) with the remaining identifiers as references. Findings whose object can't be resolved are left as is.
+func (c *checker) group(fset *token.FileSet, findings []Finding, idents []*ast.Ident) [][]int {
+	var groups [][]int
+	byKey := make(map[groupKey]int)
+
+	for i, f := range findings {
+		if !c.flags.GroupByDeclaration {
+			groups = append(groups, []int{i})
+			continue
+		}
+
+		object := c.objectPos(fset, idents[i])
+		if !object.IsValid() {
+			groups = append(groups, []int{i})
+			continue
+		}
+
+		key := groupKey{pos: object, word: f.Word}
+		j, ok := byKey[key]
+		if !ok {
+			byKey[key] = len(groups)
+			groups = append(groups, []int{i})
+			continue
+		}
+
+		if f.Declaration && !findings[groups[j][0]].Declaration {
+			// the declaration was found after a use, so it takes the use's place
+			groups[j] = append([]int{i}, groups[j]...)
+			continue
+		}
+		groups[j] = append(groups[j], i)
+	}
+
+	return groups
+}
+
+// message describes the misspelling, for example `"lable" should be label (or cable or table) in lableText`, or
+// `"Canceled" should be Cancelled (as spelled elsewhere) in StatusCanceled` for an inconsistent spelling.
+func (f Finding) message() string {
+	correction := f.Correction
+	if len(f.Alternatives) > 0 {
+		correction += fmt.Sprintf(" (or %v)", strings.Join(f.Alternatives, " or "))
+	}
+	if f.Inconsistent {
+		correction += " (as spelled elsewhere)"
+	}
+	return fmt.Sprintf("%q should be %v in %v", f.Word, correction, f.Identifier)
+}
+
+func (f Finding) location() Location {
+	return Location{Filename: f.Filename, Line: f.Line, Column: f.Column}
+}
+
+// checker holds a misspell replacer along with the flags used to filter identifiers. The replacer is compiled
+// the first time a word is checked against it, since a run where every file is cached never needs it.
+// info is optional type information for the identifiers being checked. suppressions holds the suppression
+// comments found in the files checked so far.
+type checker struct {
+	flags        Flags
+	replacer     *misspell.Replacer
+	compileOnce  sync.Once
+	info         *types.Info
+	suppressions []*suppression
+
+	// acronyms are the acronyms recognized when splitting identifiers, longest first, while segments splits run
+	// together words with flags.Segment, and is created the first time it's used
+	acronyms      []string
+	segments      *segmenter
+	segmenterOnce sync.Once
+
+	// known holds the words of flags.WordLists, and is created the first time a word isn't found in the replacer
+	known       *lexicon
+	lexiconOnce sync.Once
+
+	// kinds is the set of kinds of identifiers checked (nil to check every identifier), while declKinds holds
+	// the kind of each declaring identifier walked by checkFiles, public the positions of those declaring the
+	// public API, and walkedFiles the names of the files walked
+	kinds       map[string]bool
+	declKinds   map[*ast.Ident]string
+	public      map[token.Position]bool
+	walkedFiles map[string]bool
+
+	// addedByFile caches the lines of flags.added for each file name seen
+	addedByFile map[string]map[int]bool
+
+	// words memoizes the verdict for every word checked, while cachedFiles holds the misspelled words of each
+	// file found in flags.cache
+	words       wordCache
+	cachedFiles map[string]map[string]string
+}
+
+func newChecker(flags Flags) *checker {
+	c := &checker{
+		flags:    flags,
+		replacer: &misspell.Replacer{Replacements: misspell.DictMain},
+	}
+
+	// an invalid kind, acronym or locale has already been reported by prepare
+	c.kinds, _ = kindSet(flags.Kinds)
+	c.acronyms, _ = acronymList(flags.Acronyms)
+
+	if rules, err := localeRules(flags.Locale); err == nil {
+		c.replacer.AddRuleList(rules)
+	}
+
+	if len(flags.Corrections) > 0 {
+		// add rules in a stable order, misspell does not check for duplicates
+		misspellings := make([]string, 0, len(flags.Corrections))
+		for k := range flags.Corrections {
+			misspellings = append(misspellings, k)
+		}
+		sort.Strings(misspellings)
+
+		rules := make([]string, 0, 2*len(misspellings))
+		for i, k := range misspellings {
+			rules = append(rules, strings.ToLower(k), strings.ToLower(flags.Corrections[k]))
+			misspellings[i] = strings.ToLower(k)
+		}
+		c.replacer.RemoveRule(misspellings)
+		// the rules go ahead of misspell's, so they take precedence over shorter rules matching the same word (such
+		// as adres, which would otherwise stop a correction of adress from being found)
+		c.replacer.Replacements = append(rules, c.replacer.Replacements...)
+	}
+
+	if len(flags.Ignores) > 0 {
+		lci := strings.ToLower(flags.Ignores)
+		c.replacer.RemoveRule(strings.Split(lci, ","))
+	}
+
+	if flags.cache != nil {
+		flags.cache.useDictionary(dictionaryHash(c.replacer.Replacements, c.acronyms, c.segmentation(), c.wordLists()))
+	}
+
+	return c
+}
+
+// prepare validates flags and returns a copy with the corrections from flags.Dictionaries, the words of
+// flags.WordLists, the lines added by flags.Diff or flags.Since, and the cache in flags.Cache loaded.
+func (flags Flags) prepare() (Flags, error) {
+	if _, err := kindSet(flags.Kinds); err != nil {
+		return flags, err
+	}
+	if _, err := acronymList(flags.Acronyms); err != nil {
+		return flags, err
+	}
+	if _, err := segmentConfidence(flags.SegmentConfidence); err != nil {
+		return flags, err
+	}
+	if _, err := editDistance(flags.EditDistance); err != nil {
+		return flags, err
+	}
+	if _, err := localeRules(flags.Locale); err != nil {
+		return flags, err
+	}
+
+	added, err := flags.loadDiff()
+	if err != nil {
+		return flags, err
+	}
+	flags.added = added
+
+	if flags.Cache != "" {
+		flags.cache = loadCache(flags.Cache)
+	}
+
+	flags, err = flags.loadWordLists()
+	if err != nil {
+		return flags, err
+	}
+
+	return flags.loadDictionaries()
+}
+
+// localeRules returns the misspell rules converting spellings to locale, the same locales accepted by misspell's -locale.
+func localeRules(locale string) ([]string, error) {
+	switch strings.ToUpper(locale) {
+	case "":
+		return nil, nil
+	case "US":
+		return misspell.DictAmerican, nil
+	case "UK", "GB":
+		return misspell.DictBritish, nil
+	}
+	return nil, fmt.Errorf("unknown locale %q, must be US or UK", locale)
+}
+
+// check returns a finding for each misspelled word in ident, or nil if ident is spelled correctly
+// or is filtered out by the checker's flags.
+func (c *checker) check(fset *token.FileSet, ident *ast.Ident) []Finding {
+	var findings []Finding
+
+	filename := ""
+	if tf := fset.File(ident.Pos()); tf != nil {
+		filename = tf.Name()
+	}
+
+	for _, m := range c.misspellings(filename, ident.Name) {
+		kind, ok := c.reportedKind(fset, ident)
+		if !ok {
+			break
+		}
+		findings = append(findings, c.finding(fset, ident, kind, m))
+	}
+
+	return findings
+}
+
+// reportedKind returns the kind of ident, and whether typos in it are reported with the checker's kinds,
+// flags.DeclarationsOnly and flags.ExportedOnly. Identifiers are filtered after their words are looked up, so the
+// words of every identifier are cached whatever the flags.
+func (c *checker) reportedKind(fset *token.FileSet, ident *ast.Ident) (string, bool) {
+	kind := c.kind(ident)
+	if c.kinds != nil && !c.kinds[kind] {
+		return kind, false
+	}
+	if c.flags.DeclarationsOnly && !c.isDeclaration(ident) {
+		return kind, false
+	}
+	if c.flags.ExportedOnly && !c.declaredPublic(fset, ident) {
+		return kind, false
+	}
+	return kind, true
+}
+
+// finding returns the finding for the misspelled word m of ident, of the given kind.
+func (c *checker) finding(fset *token.FileSet, ident *ast.Ident, kind string, m misspelling) Finding {
+	pos := fset.Position(ident.Pos())
+	return Finding{
+		Filename: pos.Filename,
+		Line:     pos.Line,
+		Column:   pos.Column,
+		Word:     m.text,
+		Offset:   m.offset,
+		Length:   len(m.text),
+		// convert any hyphenated words into camelCase (or snake_case)
+		Correction:   joinCorrection(ident.Name, m.correction),
+		Alternatives: m.alternatives,
+		Identifier:   ident.Name,
+		Kind:         kind,
+		Declaration:  c.isDeclaration(ident),
+		Severity:     c.severity(fset, ident),
+		Inconsistent: m.inconsistent,
+	}
+}
+
+// misspelling is a misspelled word of an identifier, along with its correction and any alternatives (see verdict).
+// inconsistent is set for words that aren't misspelled, but are spelled differently elsewhere (see inconsistencies).
+type misspelling struct {
+	word
+	correction   string
+	alternatives []string
+	inconsistent bool
+}
+
+// misspellings returns the misspelled words of name, found in filename, in order. With flags.Segment, words that
+// are spelled correctly as far as the dictionary is concerned are also split into run together words (see segmenter).
+func (c *checker) misspellings(filename, name string) []misspelling {
+	var misspellings []misspelling
+
+	for _, w := range splitIdentifier(name, c.acronyms) {
+		if v := c.replace(filename, w.text); v.misspelled {
+			misspellings = append(misspellings, misspelling{word: w, correction: v.correction, alternatives: v.alternatives})
+			continue
+		}
+
+		if !c.flags.Segment {
+			continue
+		}
+		for _, s := range c.segmenter().segment(w.text) {
+			if v := c.replace(filename, s.text); v.misspelled {
+				s.offset += w.offset
+				misspellings = append(misspellings, misspelling{word: s, correction: v.correction, alternatives: v.alternatives})
+			}
+		}
+	}
+
+	return misspellings
+}
+
+// isDeclaration reports whether ident declares a name. Type information is used when available, otherwise
+// the identifier is compared against the declaration of the object it was resolved to by the parser, or
+// looked up in the declarations walked by checkFiles.
+func (c *checker) isDeclaration(ident *ast.Ident) bool {
+	if c.info != nil {
+		if _, ok := c.info.Defs[ident]; ok {
+			return true
+		}
+		if _, ok := c.info.Uses[ident]; ok {
+			return false
+		}
+	}
+
+	if ident.Obj != nil {
+		return ident.Obj.Pos() == ident.Pos()
+	}
+
+	// methods, fields and the like aren't resolved by the parser
+	_, ok := c.declKinds[ident]
+	return ok
+}
+
+// objectPos returns the position of the declaration of the object ident refers to, or an invalid
+// position if it can't be resolved.
+func (c *checker) objectPos(fset *token.FileSet, ident *ast.Ident) token.Position {
+	if c.info != nil {
+		if obj := c.info.Defs[ident]; obj != nil {
+			return fset.Position(obj.Pos())
+		}
+		if obj := c.info.Uses[ident]; obj != nil {
+			return fset.Position(obj.Pos())
+		}
+	}
+
+	if ident.Obj != nil {
+		return fset.Position(ident.Obj.Pos())
+	}
+
+	return token.Position{}
+}
+
+// returnsVisitor collects every identifier visited, along with the kinds of the declaring identifiers among them,
+// the identifiers declaring the public API of each file, and the names of the files visited.
 type returnsVisitor struct {
 	f           *token.FileSet
 	identifiers []*ast.Ident
-	replacer    *misspell.Replacer
+	kinds       map[*ast.Ident]string
+	public      []*ast.Ident
+	files       map[string]bool
 }
 
 func (v *returnsVisitor) Visit(node ast.Node) ast.Visitor {
 	funcDecl, ok := node.(*ast.Ident)
 	if !ok {
+		if node != nil {
+			if v.kinds == nil {
+				v.kinds = make(map[*ast.Ident]string)
+				v.files = make(map[string]bool)
+			}
+			declarationKinds(v.kinds, node)
+		}
+		if f, ok := node.(*ast.File); ok {
+			if tf := v.f.File(f.Pos()); tf != nil {
+				v.public = append(v.public, publicDeclarations(tf.Name(), f)...)
+				v.files[tf.Name()] = true
+			}
+		}
 		return v
 	}
 
@@ -7,6 +7,7 @@ import (
 	"go/token"
 	"log"
 	"os"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -25,14 +26,14 @@ func Test_CheckForIdentiferTypos(t *testing.T) {
 		{name: "default flags, specifying package and ignoring tests",
 			args: args{
 				wantLogs: []string{
-					"testdata/file.go:6 \"begining\" should be beginning in begining\n",
-					"testdata/file.go:9 \"succesful\" should be successful in succesful\n",
-					"testdata/file.go:12 \"succesful\" should be successful in succesful\n",
-					"testdata/file.go:12 \"begining\" should be beginning in begining\n",
-					"testdata/file.go:15 \"Succesful\" should be Successful in constantSuccesful\n",
-					"testdata/file.go:19 \"authorithy\" should be authority in authorithyLoop\n",
-					"testdata/file.go:22 \"authorithy\" should be authority in authorithyLoop\n",
-					"testdata/file.go:26 \"Succesful\" should be Successful in varSuccesful\n",
+					"testdata/file.go:6:6 \"begining\" should be beginning in begining\n",
+					"testdata/file.go:9:6 \"succesful\" should be successful in succesful\n",
+					"testdata/file.go:12:10 \"succesful\" should be successful in succesful\n",
+					"testdata/file.go:12:21 \"begining\" should be beginning in begining\n",
+					"testdata/file.go:15:15 \"Succesful\" should be Successful in constantSuccesful\n",
+					"testdata/file.go:19:1 \"authorithy\" should be authority in authorithyLoop\n",
+					"testdata/file.go:22:12 \"authorithy\" should be authority in authorithyLoop\n",
+					"testdata/file.go:26:8 \"Succesful\" should be Successful in varSuccesful\n",
 				},
 				flags: Flags{
 					Ignores:      "",
@@ -46,22 +47,22 @@ func Test_CheckForIdentiferTypos(t *testing.T) {
 		{name: "default flags, specifying individual files",
 			args: args{
 				wantLogs: []string{
-					"testdata/file_test.go:8 \"Begining\" should be Beginning in testBegining\n",
-					"testdata/file_test.go:11 \"Succesful\" should be Successful in testSuccesful\n",
-					"testdata/file_test.go:14 \"Succesful\" should be Successful in testSuccesful\n",
-					"testdata/file_test.go:14 \"begining\" should be beginning in begining\n",
-					"testdata/file_test.go:17 \"Succesful\" should be Successful in testConstantSuccesful\n",
-					"testdata/file_test.go:20 \"Succesful\" should be Successful in TestSuccesful\n",
-					"testdata/file_test.go:21 \"authorithy\" should be authority in authorithyLoop\n",
-					"testdata/file_test.go:24 \"authorithy\" should be authority in authorithyLoop\n",
-					"testdata/file.go:6 \"begining\" should be beginning in begining\n",
-					"testdata/file.go:9 \"succesful\" should be successful in succesful\n",
-					"testdata/file.go:12 \"succesful\" should be successful in succesful\n",
-					"testdata/file.go:12 \"begining\" should be beginning in begining\n",
-					"testdata/file.go:15 \"Succesful\" should be Successful in constantSuccesful\n",
-					"testdata/file.go:19 \"authorithy\" should be authority in authorithyLoop\n",
-					"testdata/file.go:22 \"authorithy\" should be authority in authorithyLoop\n",
-					"testdata/file.go:26 \"Succesful\" should be Successful in varSuccesful\n",
+					"testdata/file_test.go:8:10 \"Begining\" should be Beginning in testBegining\n",
+					"testdata/file_test.go:11:10 \"Succesful\" should be Successful in testSuccesful\n",
+					"testdata/file_test.go:14:14 \"Succesful\" should be Successful in testSuccesful\n",
+					"testdata/file_test.go:14:25 \"begining\" should be beginning in begining\n",
+					"testdata/file_test.go:17:19 \"Succesful\" should be Successful in testConstantSuccesful\n",
+					"testdata/file_test.go:20:10 \"Succesful\" should be Successful in TestSuccesful\n",
+					"testdata/file_test.go:21:1 \"authorithy\" should be authority in authorithyLoop\n",
+					"testdata/file_test.go:24:12 \"authorithy\" should be authority in authorithyLoop\n",
+					"testdata/file.go:6:6 \"begining\" should be beginning in begining\n",
+					"testdata/file.go:9:6 \"succesful\" should be successful in succesful\n",
+					"testdata/file.go:12:10 \"succesful\" should be successful in succesful\n",
+					"testdata/file.go:12:21 \"begining\" should be beginning in begining\n",
+					"testdata/file.go:15:15 \"Succesful\" should be Successful in constantSuccesful\n",
+					"testdata/file.go:19:1 \"authorithy\" should be authority in authorithyLoop\n",
+					"testdata/file.go:22:12 \"authorithy\" should be authority in authorithyLoop\n",
+					"testdata/file.go:26:8 \"Succesful\" should be Successful in varSuccesful\n",
 				},
 				flags: Flags{
 					Ignores:      "",
@@ -76,14 +77,14 @@ func Test_CheckForIdentiferTypos(t *testing.T) {
 		{name: "only functions",
 			args: args{
 				wantLogs: []string{
-					"testdata/file.go:6 \"begining\" should be beginning in begining\n",
-					"testdata/file_test.go:8 \"Begining\" should be Beginning in testBegining\n",
-					"testdata/file_test.go:20 \"Succesful\" should be Successful in TestSuccesful\n",
+					"testdata/file.go:6:6 \"begining\" should be beginning in begining\n",
+					"testdata/file_test.go:8:10 \"Begining\" should be Beginning in testBegining\n",
+					"testdata/file_test.go:20:10 \"Succesful\" should be Successful in TestSuccesful\n",
 				},
 				flags: Flags{
-					Ignores:       "",
-					IncludeTests:  true,
-					FunctionsOnly: true,
+					Ignores:      "",
+					IncludeTests: true,
+					Kinds:        []string{KindFunc},
 				},
 				cliArgs: []string{
 					"testdata/file.go",
@@ -94,13 +95,13 @@ func Test_CheckForIdentiferTypos(t *testing.T) {
 		{name: "only constants",
 			args: args{
 				wantLogs: []string{
-					"testdata/file.go:15 \"Succesful\" should be Successful in constantSuccesful\n",
-					"testdata/file_test.go:17 \"Succesful\" should be Successful in testConstantSuccesful\n",
+					"testdata/file.go:15:15 \"Succesful\" should be Successful in constantSuccesful\n",
+					"testdata/file_test.go:17:19 \"Succesful\" should be Successful in testConstantSuccesful\n",
 				},
 				flags: Flags{
-					Ignores:       "",
-					IncludeTests:  true,
-					ConstantsOnly: true,
+					Ignores:      "",
+					IncludeTests: true,
+					Kinds:        []string{KindConst},
 				},
 				cliArgs: []string{
 					"testdata/file.go",
@@ -111,12 +112,12 @@ func Test_CheckForIdentiferTypos(t *testing.T) {
 		{name: "only variables",
 			args: args{
 				wantLogs: []string{
-					"testdata/file.go:26 \"Succesful\" should be Successful in varSuccesful\n",
+					"testdata/file.go:26:8 \"Succesful\" should be Successful in varSuccesful\n",
 				},
 				flags: Flags{
-					Ignores:       "",
-					IncludeTests:  true,
-					VariablesOnly: true,
+					Ignores:      "",
+					IncludeTests: true,
+					Kinds:        []string{KindVar},
 				},
 				cliArgs: []string{
 					"testdata/file.go",
@@ -170,7 +171,7 @@ func Test_processIdentifiers(t *testing.T) {
 						func Propogate() {
 						}`,
 						name:     "file.go",
-						wantLogs: []string{"file.go:2 \"Propogate\" should be Propagate in Propogate\n"},
+						wantLogs: []string{"file.go:2:12 \"Propogate\" should be Propagate in Propogate\n"},
 					},
 				},
 				flags: Flags{
@@ -186,7 +187,7 @@ func Test_processIdentifiers(t *testing.T) {
 						func Alltime() {
 						}`,
 						name:     "file.go",
-						wantLogs: []string{"file.go:2 \"Alltime\" should be AllTime in Alltime\n"},
+						wantLogs: []string{"file.go:2:12 \"Alltime\" should be AllTime in Alltime\n"},
 					},
 				},
 				flags: Flags{
@@ -202,7 +203,7 @@ func Test_processIdentifiers(t *testing.T) {
 						func alltime() {
 						}`,
 						name:     "file.go",
-						wantLogs: []string{"file.go:2 \"alltime\" should be allTime in alltime\n"},
+						wantLogs: []string{"file.go:2:12 \"alltime\" should be allTime in alltime\n"},
 					},
 				},
 				flags: Flags{
@@ -250,14 +251,14 @@ func Test_processIdentifiers(t *testing.T) {
 					func PropogateMispellings() {
 					}`,
 						name:     "file1.go",
-						wantLogs: []string{"file1.go:2 \"Propogate\" should be Propagate in PropogateMispellings\n"},
+						wantLogs: []string{"file1.go:2:11 \"Propogate\" should be Propagate in PropogateMispellings\n"},
 					},
 					{
 						src: `package main
 					func AuthorithyFunc() {
 					}`,
 						name:     "file2.go",
-						wantLogs: []string{"file2.go:2 \"Authorithy\" should be Authority in AuthorithyFunc\n"},
+						wantLogs: []string{"file2.go:2:11 \"Authorithy\" should be Authority in AuthorithyFunc\n"},
 					},
 				},
 				flags: Flags{
@@ -276,8 +277,8 @@ func Test_processIdentifiers(t *testing.T) {
 						`,
 						name: "file.go",
 						wantLogs: []string{
-							"file.go:3 \"acheivement\" should be achievement in acheivement\n",
-							"file.go:4 \"creater\" should be creature in creater\n",
+							"file.go:3:21 \"acheivement\" should be achievement in acheivement\n",
+							"file.go:4:12 \"creater\" should be creature in creater\n",
 						},
 					},
 				},
@@ -297,8 +298,8 @@ func Test_processIdentifiers(t *testing.T) {
 						}`,
 						name: "file.go",
 						wantLogs: []string{
-							"file.go:3 \"begining\" should be beginning in begining\n",
-							"file.go:4 \"inital\" should be initial in inital\n",
+							"file.go:3:8 \"begining\" should be beginning in begining\n",
+							"file.go:4:8 \"inital\" should be initial in inital\n",
 						},
 					},
 				},
@@ -316,7 +317,7 @@ func Test_processIdentifiers(t *testing.T) {
 						`,
 						name: "file1.go",
 						wantLogs: []string{
-							"file1.go:2 \"begining\" should be beginning in begining\n",
+							"file1.go:2:14 \"begining\" should be beginning in begining\n",
 						},
 					},
 					{
@@ -325,7 +326,7 @@ func Test_processIdentifiers(t *testing.T) {
 						`,
 						name: "file1.go",
 						wantLogs: []string{
-							"file1.go:2 \"inital\" should be initial in inital\n",
+							"file1.go:2:14 \"inital\" should be initial in inital\n",
 						},
 					},
 				},
@@ -344,8 +345,8 @@ func Test_processIdentifiers(t *testing.T) {
 						`,
 						name: "file.go",
 						wantLogs: []string{
-							"file.go:2 \"begining\" should be beginning in begining\n",
-							"file.go:3 \"inital\" should be initial in inital\n",
+							"file.go:2:14 \"begining\" should be beginning in begining\n",
+							"file.go:3:14 \"inital\" should be initial in inital\n",
 						},
 					},
 				},
@@ -366,8 +367,8 @@ func Test_processIdentifiers(t *testing.T) {
 						`,
 						name: "file.go",
 						wantLogs: []string{
-							"file.go:3 \"begining\" should be beginning in begining\n",
-							"file.go:4 \"inital\" should be initial in inital\n",
+							"file.go:3:11 \"begining\" should be beginning in begining\n",
+							"file.go:4:11 \"inital\" should be initial in inital\n",
 						},
 					},
 				},
@@ -389,12 +390,12 @@ func Test_processIdentifiers(t *testing.T) {
 					},
 				},
 				flags: Flags{
-					Ignores:       "",
-					ConstantsOnly: true,
+					Ignores: "",
+					Kinds:   []string{KindConst},
 				},
 			},
 		},
-		{name: "misspelled function/variable/constant (with functions/variables/constants=true)",
+		{name: "misspelled function/variable/constant (with func/var/const kinds)",
 			args: args{
 				testFiles: []*testFile{
 					{
@@ -404,17 +405,15 @@ func Test_processIdentifiers(t *testing.T) {
 						 func PropogateFunc() {}`,
 						name: "file.go",
 						wantLogs: []string{
-							"file.go:2 \"begining\" should be beginning in begining\n",
-							"file.go:3 \"propogate\" should be propagate in propogate\n",
-							"file.go:4 \"Propogate\" should be Propagate in PropogateFunc\n",
+							"file.go:2:13 \"begining\" should be beginning in begining\n",
+							"file.go:3:11 \"propogate\" should be propagate in propogate\n",
+							"file.go:4:13 \"Propogate\" should be Propagate in PropogateFunc\n",
 						},
 					},
 				},
 				flags: Flags{
-					Ignores:       "",
-					FunctionsOnly: true,
-					VariablesOnly: true,
-					ConstantsOnly: true,
+					Ignores: "",
+					Kinds:   []string{KindFunc, KindVar, KindConst},
 				},
 			},
 		},
@@ -433,14 +432,14 @@ func Test_processIdentifiers(t *testing.T) {
 						const BeginingBar = 0`,
 						name: "file.go",
 						wantLogs: []string{
-							"file.go:2 \"begining\" should be beginning in begining\n",
-							"file.go:3 \"Begining\" should be Beginning in Begining\n",
-							"file.go:4 \"Begining\" should be Beginning in fooBegining\n",
-							"file.go:5 \"Begining\" should be Beginning in fooBeginingBar\n",
-							"file.go:6 \"Begining\" should be Beginning in FooBeginingBar\n",
-							"file.go:7 \"Begining\" should be Beginning in FooBegining\n",
-							"file.go:8 \"begining\" should be beginning in beginingBar\n",
-							"file.go:9 \"Begining\" should be Beginning in BeginingBar\n",
+							"file.go:2:13 \"begining\" should be beginning in begining\n",
+							"file.go:3:13 \"Begining\" should be Beginning in Begining\n",
+							"file.go:4:16 \"Begining\" should be Beginning in fooBegining\n",
+							"file.go:5:16 \"Begining\" should be Beginning in fooBeginingBar\n",
+							"file.go:6:16 \"Begining\" should be Beginning in FooBeginingBar\n",
+							"file.go:7:16 \"Begining\" should be Beginning in FooBegining\n",
+							"file.go:8:13 \"begining\" should be beginning in beginingBar\n",
+							"file.go:9:13 \"Begining\" should be Beginning in BeginingBar\n",
 						},
 					},
 				},
@@ -456,7 +455,7 @@ func Test_processIdentifiers(t *testing.T) {
 						src:  `package inital`,
 						name: "file.go",
 						wantLogs: []string{
-							"file.go:1 \"inital\" should be initial in inital\n",
+							"file.go:1:9 \"inital\" should be initial in inital\n",
 						},
 					},
 				},
@@ -478,7 +477,7 @@ func Test_processIdentifiers(t *testing.T) {
 								}`,
 						name: "file.go",
 						wantLogs: []string{
-							"file.go:3 \"inital\" should be initial in initalLabel\n",
+							"file.go:3:9 \"inital\" should be initial in initalLabel\n",
 						},
 					},
 				},
@@ -496,7 +495,7 @@ func Test_processIdentifiers(t *testing.T) {
 								`,
 						name: "file.go",
 						wantLogs: []string{
-							"file.go:2 \"inital\" should be initial in initalType\n",
+							"file.go:2:14 \"inital\" should be initial in initalType\n",
 						},
 					},
 				},
@@ -516,7 +515,7 @@ func Test_processIdentifiers(t *testing.T) {
 								`,
 						name: "file.go",
 						wantLogs: []string{
-							"file.go:3 \"inital\" should be initial in inital\n",
+							"file.go:3:10 \"inital\" should be initial in inital\n",
 						},
 					},
 				},
@@ -536,12 +535,127 @@ func Test_processIdentifiers(t *testing.T) {
 								`,
 						name: "file.go",
 						wantLogs: []string{
-							"file.go:3 \"inital\" should be initial in inital\n",
+							"file.go:3:19 \"inital\" should be initial in inital\n",
+						},
+					},
+				},
+				flags: Flags{
+					Ignores: "",
+				},
+			},
+		},
+		{name: "misspelled variable grouped by declaration",
+			args: args{
+				testFiles: []*testFile{
+					{
+						src: `package main
+								func main() {
+									begining := true
+									_ = begining
+									begining = false
+									a.inital()
+									a.inital()
+								}
+								`,
+						name: "file.go",
+						wantLogs: []string{
+							"file.go:3:10 \"begining\" should be beginning in begining (2 references)\n",
+							"file.go:6:12 \"inital\" should be initial in inital\n",
+							"file.go:7:12 \"inital\" should be initial in inital\n",
+						},
+					},
+				},
+				flags: Flags{
+					Ignores:            "",
+					GroupByDeclaration: true,
+				},
+			},
+		},
+		{name: "misspelled label grouped by declaration",
+			args: args{
+				testFiles: []*testFile{
+					{
+						src: `package main
+								func main() {
+								initalLabel:
+									for i := 0; i < 5; i++ {
+										continue initalLabel
+									}
+								}`,
+						name: "file.go",
+						wantLogs: []string{
+							"file.go:3:9 \"inital\" should be initial in initalLabel (1 reference)\n",
+						},
+					},
+				},
+				flags: Flags{
+					Ignores:            "",
+					GroupByDeclaration: true,
+				},
+			},
+		},
+		{name: "misspelling from additional corrections",
+			args: args{
+				testFiles: []*testFile{
+					{
+						src: `package main
+								type TennantID string
+								func idempotant() {}
+								`,
+						name: "file.go",
+						wantLogs: []string{
+							"file.go:2:14 \"Tennant\" should be Tenant in TennantID\n",
+							"file.go:3:14 \"idempotant\" should be idempotent in idempotant\n",
+						},
+					},
+				},
+				flags: Flags{
+					Ignores:     "",
+					Corrections: map[string]string{"tennant": "tenant", "Idempotant": "idempotent"},
+				},
+			},
+		},
+		{name: "additional corrections replacing misspell's",
+			args: args{
+				testFiles: []*testFile{
+					{
+						src: `package main
+								type Location struct { Adress string }
+								`,
+						name: "file.go",
+						wantLogs: []string{
+							"file.go:2:32 \"Adress\" should be Address in Adress\n",
+						},
+					},
+				},
+				flags: Flags{
+					Ignores:     "",
+					Corrections: map[string]string{"adress": "address"},
+				},
+			},
+		},
+		{name: "excluded files",
+			args: args{
+				testFiles: []*testFile{
+					{
+						src: `package main
+								var begining = true
+								`,
+						name: "file_gen.go",
+					},
+					{
+						src: `package main
+								var inital = true
+								`,
+						name: "file.go",
+						wantLogs: []string{
+							"file.go:2:13 \"inital\" should be initial in inital\n",
 						},
 					},
 				},
 				flags: Flags{
 					Ignores: "",
+					Exclude: []string{"*_gen.go"},
 				},
 			},
 		},
@@ -564,7 +678,7 @@ func Test_processIdentifiers(t *testing.T) {
 			log.SetOutput(&buf)
 			defer log.SetOutput(os.Stderr)
 
-			err := processIdentifiers(fset, files, tt.args.flags)
+			err := processIdentifiers(fset, files, nil, tt.args.flags)
 			if err != nil {
 				t.Fatalf("processIdentifiers %v", err)
 			}
@@ -580,3 +694,235 @@ func Test_processIdentifiers(t *testing.T) {
 		})
 	}
 }
+
+func Test_FindIdentifierTypos(t *testing.T) {
+	want := []Finding{
+		{Filename: "testdata/file.go", Line: 6, Column: 6, Word: "begining", Offset: 0, Length: 8, Correction: "beginning", Identifier: "begining", Kind: "func", Declaration: true, Severity: "internal"},
+		{Filename: "testdata/file.go", Line: 9, Column: 6, Word: "succesful", Offset: 0, Length: 9, Correction: "successful", Identifier: "succesful", Kind: "type", Declaration: true, Severity: "internal"},
+		{Filename: "testdata/file.go", Line: 12, Column: 10, Word: "succesful", Offset: 0, Length: 9, Correction: "successful", Identifier: "succesful", Kind: "type", Severity: "internal"},
+		{Filename: "testdata/file.go", Line: 12, Column: 21, Word: "begining", Offset: 0, Length: 8, Correction: "beginning", Identifier: "begining", Kind: "method", Declaration: true, Severity: "internal"},
+		{Filename: "testdata/file.go", Line: 15, Column: 7, Word: "Succesful", Offset: 8, Length: 9, Correction: "Successful", Identifier: "constantSuccesful", Kind: "const", Declaration: true, Severity: "internal"},
+		{Filename: "testdata/file.go", Line: 19, Column: 1, Word: "authorithy", Offset: 0, Length: 10, Correction: "authority", Identifier: "authorithyLoop", Kind: "label", Declaration: true, Severity: "internal"},
+		{Filename: "testdata/file.go", Line: 22, Column: 12, Word: "authorithy", Offset: 0, Length: 10, Correction: "authority", Identifier: "authorithyLoop", Kind: "label", Severity: "internal"},
+		{Filename: "testdata/file.go", Line: 26, Column: 5, Word: "Succesful", Offset: 3, Length: 9, Correction: "Successful", Identifier: "varSuccesful", Kind: "var", Declaration: true, Severity: "internal"},
+	}
+
+	got, err := FindIdentifierTypos([]string{"testdata/file.go"}, Flags{})
+	if err != nil {
+		t.Fatalf("FindIdentifierTypos %v", err)
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("\ngot %v findings: %v\nexp %v findings: %v\n", len(got), got, len(want), want)
+	}
+	for i := range want {
+		if !reflect.DeepEqual(got[i], want[i]) {
+			t.Errorf("finding %d\ngot %#v\nexp %#v\n", i, got[i], want[i])
+		}
+	}
+}
+
+func Test_FindIdentifierTyposSnakeCase(t *testing.T) {
+	want := []string{
+		"testdata/snake/snake.go:4:9 \"recieve\" should be receive in max_recieve_attempts",
+		"testdata/snake/snake.go:7:15 \"INITAL\" should be INITIAL in DEFAULT_INITAL_TIMEOUT [public API]",
+		"testdata/snake/snake.go:10:6 \"retreive\" should be retrieve in _retreive",
+		"testdata/snake/snake.go:10:16 \"ALLTIME\" should be ALL_TIME in ALLTIME_ [public API]",
+	}
+
+	findings, err := FindIdentifierTypos([]string{"testdata/snake/snake.go"}, Flags{})
+	if err != nil {
+		t.Fatalf("FindIdentifierTypos %v", err)
+	}
+
+	var got []string
+	for _, f := range findings {
+		got = append(got, f.String())
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("\ngot %q\nexp %q\n", got, want)
+	}
+}
+
+func Test_FindIdentifierTyposDeclarationsOnly(t *testing.T) {
+	// dep stands in for a third-party package, which isn't checked
+	dir, _ := fixture(t, map[string]string{
+		"go.mod":     "module example.com/fixture\n",
+		"dep/dep.go": "package dep\n\nfunc RecieveInstance() {}\n",
+		"app/app.go": "package app\n\nimport \"example.com/fixture/dep\"\n\nfunc begining() {\n\tdep.RecieveInstance()\n}\n\nvar _ = begining\n",
+	})
+
+	defer inModule(t, dir)()
+
+	tests := []struct {
+		name  string
+		flags Flags
+		want  []string
+	}{
+		{name: "every identifier",
+			flags: Flags{},
+			want: []string{
+				"app/app.go:5:6 \"begining\" should be beginning in begining",
+				"app/app.go:6:6 \"Recieve\" should be Receive in RecieveInstance [public API]",
+				"app/app.go:9:9 \"begining\" should be beginning in begining",
+			},
+		},
+		{name: "declarations only",
+			flags: Flags{DeclarationsOnly: true},
+			want: []string{
+				"app/app.go:5:6 \"begining\" should be beginning in begining",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings, err := FindIdentifierTypos([]string{"./app"}, tt.flags)
+			if err != nil {
+				t.Fatalf("FindIdentifierTypos %v", err)
+			}
+
+			var got []string
+			for _, f := range findings {
+				got = append(got, f.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("\ngot %q\nexp %q\n", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_processIdentifiersExitStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		flags   Flags
+		wantErr error
+	}{
+		{name: "typos found without exit status",
+			src:     `package main; func Propogate() {}`,
+			flags:   Flags{},
+			wantErr: nil,
+		},
+		{name: "typos found with exit status",
+			src:     `package main; func Propogate() {}`,
+			flags:   Flags{SetExitStatus: true},
+			wantErr: ErrIssuesFound,
+		},
+		{name: "no typos found with exit status",
+			src:     `package main; func Propagate() {}`,
+			flags:   Flags{SetExitStatus: true},
+			wantErr: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fset := token.NewFileSet()
+			f, err := parser.ParseFile(fset, "file.go", tt.src, 0)
+			if err != nil {
+				t.Fatalf("Did not expect error parsing file, %v", err)
+			}
+
+			var buf bytes.Buffer
+			log.SetFlags(0)
+			log.SetOutput(&buf)
+			defer log.SetOutput(os.Stderr)
+
+			if err := processIdentifiers(fset, []*ast.File{f}, nil, tt.flags); err != tt.wantErr {
+				t.Fatalf("processIdentifiers() = %v, exp %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_FindIdentifierTyposGroupByDeclaration(t *testing.T) {
+	want := []string{
+		"testdata/file.go:6:6 \"begining\" should be beginning in begining",
+		"testdata/file.go:9:6 \"succesful\" should be successful in succesful (1 reference)",
+		"testdata/file.go:12:21 \"begining\" should be beginning in begining",
+		"testdata/file.go:15:15 \"Succesful\" should be Successful in constantSuccesful",
+		"testdata/file.go:19:1 \"authorithy\" should be authority in authorithyLoop (1 reference)",
+		"testdata/file.go:26:8 \"Succesful\" should be Successful in varSuccesful",
+	}
+
+	got, err := FindIdentifierTypos([]string{"testdata/file.go"}, Flags{GroupByDeclaration: true})
+	if err != nil {
+		t.Fatalf("FindIdentifierTypos %v", err)
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("\ngot %v\nexp %v\n", got, want)
+	}
+	for i := range want {
+		if got[i].String() != want[i] {
+			t.Errorf("\ngot %v\nexp %v\n", got[i], want[i])
+		}
+	}
+
+	wantRef := Location{Filename: "testdata/file.go", Line: 22, Column: 12}
+	if refs := got[4].References; len(refs) != 1 || refs[0] != wantRef {
+		t.Errorf("\ngot references %v\nexp %v\n", refs, wantRef)
+	}
+}
+
+func Test_findTyposLocale(t *testing.T) {
+	src := `package main
+
+type DefaultColourScheme struct{}
+
+func normalizeColorBehavior() {}
+
+var initialisedFromConfig bool
+`
+	tests := []struct {
+		name   string
+		locale string
+		want   []string
+	}{
+		{name: "neutral",
+			locale: "",
+			want:   nil,
+		},
+		{name: "US",
+			locale: "US",
+			want: []string{
+				"file.go:3:13 \"Colour\" should be Color in DefaultColourScheme",
+				"file.go:7:5 \"initialised\" should be initialized in initialisedFromConfig",
+			},
+		},
+		{name: "UK",
+			locale: "uk",
+			want: []string{
+				"file.go:5:6 \"normalize\" should be normalise in normalizeColorBehavior",
+				"file.go:5:15 \"Color\" should be Colour in normalizeColorBehavior",
+				"file.go:5:20 \"Behavior\" should be Behaviour in normalizeColorBehavior",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flags, err := Flags{Locale: tt.locale}.prepare()
+			if err != nil {
+				t.Fatalf("prepare %v", err)
+			}
+
+			fset := token.NewFileSet()
+			f, err := parser.ParseFile(fset, "file.go", src, 0)
+			if err != nil {
+				t.Fatalf("Did not expect error parsing file, %v", err)
+			}
+
+			var got []string
+			for _, finding := range findTypos(fset, []*ast.File{f}, nil, flags) {
+				got = append(got, finding.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("\ngot %q\nexp %q\n", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := (Flags{Locale: "NZ"}).prepare(); err == nil {
+		t.Fatalf("expected error for unknown locale")
+	}
+}
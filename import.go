@@ -3,402 +3,275 @@ package identypo
 import (
 	"fmt"
 	"go/ast"
-	"go/build"
 	"go/parser"
 	"go/token"
-	"log"
+	"go/types"
 	"os"
-	"path"
 	"path/filepath"
-	"regexp"
-	"runtime"
+	"sort"
 	"strings"
+
+	"golang.org/x/tools/go/packages"
 )
 
 const (
 	pwd = "./"
 )
 
-var buildContext = build.Default
-
-var (
-	goroot    = filepath.Clean(runtime.GOROOT())
-	gorootSrc = filepath.Join(goroot, "src")
-)
+// loadMode is the information requested from go/packages for every package identypo loads.
+const loadMode = packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles | packages.NeedSyntax | packages.NeedTypes | packages.NeedTypesInfo
 
-func parseInput(args []string, fset *token.FileSet, includeTests bool) ([]*ast.File, error) {
-	var directoryList []string
-	var fileMode bool
-	files := make([]*ast.File, 0)
+// parseInput loads the files named by args using go/packages, so module mode (go.mod, go.work, replace directives)
+// and GOPATH mode projects are both supported. args may be file names, directories, or package patterns
+// (with or without the ... wildcard). Files named explicitly are returned in the order they were given.
+// The returned type information covers every returned file, but may be incomplete if a package has type errors.
+// At most workers files are parsed at once.
+func parseInput(args []string, fset *token.FileSet, includeTests bool, workers int) ([]*ast.File, *types.Info, error) {
+	var patterns []string
+	var fileArgs []string
 
 	if len(args) == 0 {
-		directoryList = append(directoryList, pwd)
-	} else {
-		for _, arg := range args {
-			if strings.HasSuffix(arg, "/...") && isDir(arg[:len(arg)-len("/...")]) {
-
-				directoryList = append(directoryList, allPackagesInFS(arg)...)
-
-			} else if isDir(arg) {
-				directoryList = append(directoryList, arg)
-
-			} else if exists(arg) {
-				if strings.HasSuffix(arg, ".go") {
-					fileMode = true
-					f, err := parser.ParseFile(fset, arg, nil, 0)
-					if err != nil {
-						return nil, err
-					}
-					files = append(files, f)
-				} else {
-					return nil, fmt.Errorf("invalid file %v specified", arg)
-				}
-			} else {
-
-				imPaths := importPaths([]string{arg})
-				for _, importPath := range imPaths {
-					pkg, err := build.Import(importPath, ".", 0)
-					if err != nil {
-						return nil, err
-					}
-					var stringFiles []string
-					stringFiles = append(stringFiles, pkg.GoFiles...)
-					stringFiles = append(stringFiles, pkg.TestGoFiles...)
-					if pkg.Dir != "." {
-						for i, f := range stringFiles {
-							stringFiles[i] = filepath.Join(pkg.Dir, f)
-						}
-					}
-
-					fileMode = true
-					for _, stringFile := range stringFiles {
-						f, err := parser.ParseFile(fset, stringFile, nil, 0)
-						if err != nil {
-							return nil, err
-						}
-						files = append(files, f)
-					}
-
-				}
+		patterns = append(patterns, pwd)
+	}
+
+	for _, arg := range args {
+		if strings.HasSuffix(arg, "/...") && isDir(arg[:len(arg)-len("/...")]) {
+			patterns = append(patterns, localPattern(arg))
+		} else if isDir(arg) {
+			patterns = append(patterns, localPattern(arg))
+		} else if exists(arg) {
+			if !strings.HasSuffix(arg, ".go") {
+				return nil, nil, fmt.Errorf("invalid file %v specified", arg)
 			}
+			fileArgs = append(fileArgs, arg)
+		} else {
+			// anything else is handed to the go command as an import path pattern
+			patterns = append(patterns, arg)
 		}
 	}
 
-	// if we're not in file mode, then we need to grab each and every package in each directory
-	// we can to grab all the files
-	if !fileMode {
-		for _, fpath := range directoryList {
-			pkgs, err := parser.ParseDir(fset, fpath, nil, 0)
-			if err != nil {
-				return nil, err
-			}
+	var files []*ast.File
+	info := newInfo()
+	if workers < 1 {
+		workers = 1
+	}
+	parsing := make(chan struct{}, workers)
 
-			for _, pkg := range pkgs {
-				for _, f := range pkg.Files {
-					files = append(files, f)
-				}
-			}
+	if len(patterns) > 0 {
+		pkgs, err := loadPackages(fset, patterns, parsing)
+		if err != nil {
+			return nil, nil, err
+		}
+		files = append(files, packageFiles(pkgs)...)
+		mergeInfo(info, pkgs)
+	}
+
+	if len(fileArgs) > 0 {
+		fileFiles, err := loadFiles(fset, fileArgs, info, parsing)
+		if err != nil {
+			return nil, nil, err
 		}
+		files = append(files, fileFiles...)
 	}
 
 	// do a final pass to remove tests
 	if !includeTests {
 		for i, f := range files {
-			if strings.HasSuffix(fset.File(f.Pos()).Name(), "test.go") {
+			if strings.HasSuffix(fset.File(f.Pos()).Name(), "_test.go") {
 				files[i] = nil
 			}
 		}
 	}
 
-	return files, nil
+	return files, info, nil
 }
 
-func isDir(filename string) bool {
-	fi, err := os.Stat(filename)
-	return err == nil && fi.IsDir()
-}
+// loadFiles loads Go files named on the command line. The go command requires named files to share
+// a directory, so each directory is loaded separately (as many at once as parsing allows) and the files are then
+// put back in the order they were given. Type information for the loaded files is merged into info.
+func loadFiles(fset *token.FileSet, fileArgs []string, info *types.Info, parsing chan struct{}) ([]*ast.File, error) {
+	var dirs []string
+	byDir := make(map[string][]string)
+	order := make(map[string]int)
+
+	for i, arg := range fileArgs {
+		dir := filepath.Dir(arg)
+		if _, ok := byDir[dir]; !ok {
+			dirs = append(dirs, dir)
+		}
+		byDir[dir] = append(byDir[dir], arg)
+		order[relativeName(arg)] = i
+	}
 
-func exists(filename string) bool {
-	_, err := os.Stat(filename)
-	return err == nil
+	loaded := make([][]*packages.Package, len(dirs))
+	errs := make([]error, len(dirs))
+	parallel(len(dirs), cap(parsing), func(i int) {
+		loaded[i], errs[i] = loadPackages(fset, byDir[dirs[i]], parsing)
+	})
+
+	var files []*ast.File
+	for i, pkgs := range loaded {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		files = append(files, packageFiles(pkgs)...)
+		mergeInfo(info, pkgs)
+	}
+
+	sort.SliceStable(files, func(i, j int) bool {
+		return order[fset.File(files[i].Pos()).Name()] < order[fset.File(files[j].Pos()).Name()]
+	})
+
+	return files, nil
 }
 
-// importPathsNoDotExpansion returns the import paths to use for the given
-// command line, but it does no ... expansion.
-func importPathsNoDotExpansion(args []string) []string {
-	if len(args) == 0 {
-		return []string{"."}
+// loadPackages loads patterns (including test variants) with go/packages. Errors listing or parsing
+// packages are returned, while type errors are ignored since identypo only needs best-effort type information.
+// Files are parsed concurrently, holding a slot in parsing while each is parsed to bound the number parsed at once.
+func loadPackages(fset *token.FileSet, patterns []string, parsing chan struct{}) ([]*packages.Package, error) {
+	cfg := &packages.Config{
+		Mode:  loadMode,
+		Fset:  fset,
+		Tests: true,
+		ParseFile: func(fset *token.FileSet, filename string, src []byte) (*ast.File, error) {
+			parsing <- struct{}{}
+			defer func() { <-parsing }()
+			return parseFile(fset, filename, src)
+		},
 	}
-	var out []string
-	for _, a := range args {
-		// Arguments are supposed to be import paths, but
-		// as a courtesy to Windows developers, rewrite \ to /
-		// in command-line arguments.  Handles .\... and so on.
-		if filepath.Separator == '\\' {
-			a = strings.Replace(a, `\`, `/`, -1)
-		}
 
-		// Put argument in canonical form, but preserve leading ./.
-		if strings.HasPrefix(a, "./") {
-			a = "./" + path.Clean(a)
-			if a == "./." {
-				a = "."
-			}
-		} else {
-			a = path.Clean(a)
-		}
-		if a == "all" || a == "std" {
-			out = append(out, allPackages(a)...)
-			continue
-		}
-		out = append(out, a)
+	pkgs, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		return nil, err
 	}
-	return out
-}
 
-// importPaths returns the import paths to use for the given command line.
-func importPaths(args []string) []string {
-	args = importPathsNoDotExpansion(args)
-	var out []string
-	for _, a := range args {
-		if strings.Contains(a, "...") {
-			if build.IsLocalImport(a) {
-				out = append(out, allPackagesInFS(a)...)
-			} else {
-				out = append(out, allPackages(a)...)
+	for _, pkg := range pkgs {
+		for _, pkgErr := range pkg.Errors {
+			if pkgErr.Kind != packages.TypeError {
+				return nil, pkgErr
 			}
-			continue
 		}
-		out = append(out, a)
 	}
-	return out
+
+	return pkgs, nil
 }
 
-// matchPattern(pattern)(name) reports whether
-// name matches pattern.  Pattern is a limited glob
-// pattern in which '...' means 'any string' and there
-// is no other special syntax.
-func matchPattern(pattern string) func(name string) bool {
-	re := regexp.QuoteMeta(pattern)
-	re = strings.Replace(re, `\.\.\.`, `.*`, -1)
-	// Special case: foo/... matches foo too.
-	if strings.HasSuffix(re, `/.*`) {
-		re = re[:len(re)-len(`/.*`)] + `(/.*)?`
-	}
-	reg := regexp.MustCompile(`^` + re + `$`)
-	return func(name string) bool {
-		return reg.MatchString(name)
-	}
+// parseFile parses a file for go/packages, recording its name relative to the working directory
+// where possible so output matches the paths given on the command line.
+func parseFile(fset *token.FileSet, filename string, src []byte) (*ast.File, error) {
+	return parser.ParseFile(fset, relativeName(filename), src, parser.AllErrors|parser.ParseComments)
 }
 
-// hasPathPrefix reports whether the path s begins with the
-// elements in prefix.
-func hasPathPrefix(s, prefix string) bool {
-	switch {
-	default:
-		return false
-	case len(s) == len(prefix):
-		return s == prefix
-	case len(s) > len(prefix):
-		if prefix != "" && prefix[len(prefix)-1] == '/' {
-			return strings.HasPrefix(s, prefix)
+// packageFiles returns the syntax of every file in pkgs exactly once. When a file belongs to several packages
+// (for example a package and its test variant), the syntax from the package with the most files is used, so that
+// identifiers in a file and its tests resolve to the same objects.
+func packageFiles(pkgs []*packages.Package) []*ast.File {
+	owners := make(map[string]*ast.File)
+	sizes := make(map[string]int)
+
+	for _, pkg := range pkgs {
+		for i, f := range pkg.Syntax {
+			name := pkg.CompiledGoFiles[i]
+			if _, ok := owners[name]; !ok || len(pkg.Syntax) > sizes[name] {
+				owners[name] = f
+				sizes[name] = len(pkg.Syntax)
+			}
 		}
-		return s[len(prefix)] == '/' && s[:len(prefix)] == prefix
 	}
-}
 
-// treeCanMatchPattern(pattern)(name) reports whether
-// name or children of name can possibly match pattern.
-// Pattern is the same limited glob accepted by matchPattern.
-func treeCanMatchPattern(pattern string) func(name string) bool {
-	wildCard := false
-	if i := strings.Index(pattern, "..."); i >= 0 {
-		wildCard = true
-		pattern = pattern[:i]
-	}
-	return func(name string) bool {
-		return len(name) <= len(pattern) && hasPathPrefix(pattern, name) ||
-			wildCard && strings.HasPrefix(name, pattern)
-	}
-}
+	var files []*ast.File
+	seen := make(map[string]bool)
 
-// allPackages returns all the packages that can be found
-// under the $GOPATH directories and $GOROOT matching pattern.
-// The pattern is either "all" (all packages), "std" (standard packages)
-// or a path including "...".
-func allPackages(pattern string) []string {
-	pkgs := matchPackages(pattern)
-	if len(pkgs) == 0 {
-		fmt.Fprintf(os.Stderr, "warning: %q matched no packages\n", pattern)
+	for _, pkg := range pkgs {
+		for i := range pkg.Syntax {
+			name := pkg.CompiledGoFiles[i]
+			if seen[name] || synthesized(name) {
+				continue
+			}
+			seen[name] = true
+			files = append(files, owners[name])
+		}
 	}
-	return pkgs
+
+	return files
 }
 
-func matchPackages(pattern string) []string {
-	match := func(string) bool { return true }
-	treeCanMatch := func(string) bool { return true }
-	if pattern != "all" && pattern != "std" {
-		match = matchPattern(pattern)
-		treeCanMatch = treeCanMatchPattern(pattern)
-	}
+// synthesized reports whether filename was synthesized by the build system (such as the generated test main) rather
+// than written by hand. Synthesized files live in the build cache without a .go extension, and aren't checked.
+func synthesized(filename string) bool {
+	return !strings.HasSuffix(filename, ".go")
+}
 
-	have := map[string]bool{
-		"builtin": true, // ignore pseudo-package that exists only for documentation
-	}
-	if !buildContext.CgoEnabled {
-		have["runtime/cgo"] = true // ignore during walk
+func newInfo() *types.Info {
+	return &types.Info{
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Scopes:     make(map[ast.Node]*types.Scope),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
 	}
-	var pkgs []string
+}
 
-	// Commands
-	cmd := filepath.Join(goroot, "src/cmd") + string(filepath.Separator)
-	_ = filepath.Walk(cmd, func(path string, fi os.FileInfo, err error) error {
-		if err != nil || !fi.IsDir() || path == cmd {
-			return nil
+// mergeInfo copies the type information of pkgs into info. Every package is type checked against its own
+// syntax, so the maps of different packages never share keys.
+func mergeInfo(info *types.Info, pkgs []*packages.Package) {
+	for _, pkg := range pkgs {
+		if pkg.TypesInfo == nil {
+			continue
 		}
-		name := path[len(cmd):]
-		if !treeCanMatch(name) {
-			return filepath.SkipDir
+		for k, v := range pkg.TypesInfo.Types {
+			info.Types[k] = v
 		}
-		// Commands are all in cmd/, not in subdirectories.
-		if strings.Contains(name, string(filepath.Separator)) {
-			return filepath.SkipDir
+		for k, v := range pkg.TypesInfo.Defs {
+			info.Defs[k] = v
 		}
-
-		// We use, e.g., cmd/gofmt as the pseudo import path for gofmt.
-		name = "cmd/" + name
-		if have[name] {
-			return nil
+		for k, v := range pkg.TypesInfo.Uses {
+			info.Uses[k] = v
 		}
-		have[name] = true
-		if !match(name) {
-			return nil
+		for k, v := range pkg.TypesInfo.Scopes {
+			info.Scopes[k] = v
 		}
-		_, err = buildContext.ImportDir(path, 0)
-		if err != nil {
-			if _, noGo := err.(*build.NoGoError); !noGo {
-				log.Print(err)
-			}
-			return nil
-		}
-		pkgs = append(pkgs, name)
-		return nil
-	})
-
-	for _, src := range buildContext.SrcDirs() {
-		if (pattern == "std" || pattern == "cmd") && src != gorootSrc {
-			continue
-		}
-		src = filepath.Clean(src) + string(filepath.Separator)
-		root := src
-		if pattern == "cmd" {
-			root += "cmd" + string(filepath.Separator)
+		for k, v := range pkg.TypesInfo.Selections {
+			info.Selections[k] = v
 		}
-		_ = filepath.Walk(root, func(path string, fi os.FileInfo, err error) error {
-			if err != nil || !fi.IsDir() || path == src {
-				return nil
-			}
-
-			// Avoid .foo, _foo, testdata and vendor directory trees.
-			_, elem := filepath.Split(path)
-			if strings.HasPrefix(elem, ".") || strings.HasPrefix(elem, "_") || elem == "testdata" || elem == "vendor" {
-				return filepath.SkipDir
-			}
-
-			name := filepath.ToSlash(path[len(src):])
-			if pattern == "std" && (strings.Contains(name, ".") || name == "cmd") {
-				// The name "std" is only the standard library.
-				// If the name is cmd, it's the root of the command tree.
-				return filepath.SkipDir
-			}
-			if !treeCanMatch(name) {
-				return filepath.SkipDir
-			}
-			if have[name] {
-				return nil
-			}
-			have[name] = true
-			if !match(name) {
-				return nil
-			}
-			_, err = buildContext.ImportDir(path, 0)
-			if err != nil {
-				if _, noGo := err.(*build.NoGoError); noGo {
-					return nil
-				}
-			}
-			pkgs = append(pkgs, name)
-			return nil
-		})
 	}
-	return pkgs
 }
 
-// allPackagesInFS is like allPackages but is passed a pattern
-// beginning ./ or ../, meaning it should scan the tree rooted
-// at the given directory.  There are ... in the pattern too.
-func allPackagesInFS(pattern string) []string {
-	pkgs := matchPackagesInFS(pattern)
-	if len(pkgs) == 0 {
-		fmt.Fprintf(os.Stderr, "warning: %q matched no packages\n", pattern)
+// localPattern turns a directory (or directory/... pattern) into a pattern the go command
+// treats as a path rather than an import path.
+func localPattern(arg string) string {
+	if filepath.IsAbs(arg) || strings.HasPrefix(arg, ".") {
+		return arg
 	}
-	return pkgs
+	return pwd + arg
 }
 
-func matchPackagesInFS(pattern string) []string {
-	// Find directory to begin the scan.
-	// Could be smarter but this one optimization
-	// is enough for now, since ... is usually at the
-	// end of a path.
-	i := strings.Index(pattern, "...")
-	dir, _ := path.Split(pattern[:i])
-
-	// pattern begins with ./ or ../.
-	// path.Clean will discard the ./ but not the ../.
-	// We need to preserve the ./ for pattern matching
-	// and in the returned import paths.
-	prefix := ""
-	if strings.HasPrefix(pattern, "./") {
-		prefix = "./"
+// relativeName returns filename relative to the working directory, or filename unchanged
+// if it is outside of the working directory.
+func relativeName(filename string) string {
+	if !filepath.IsAbs(filename) {
+		return filepath.Clean(filename)
 	}
-	match := matchPattern(pattern)
 
-	var pkgs []string
-	_ = filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
-		if err != nil || !fi.IsDir() {
-			return nil
-		}
-		if path == dir {
-			// filepath.Walk starts at dir and recurses. For the recursive case,
-			// the path is the result of filepath.Join, which calls filepath.Clean.
-			// The initial case is not Cleaned, though, so we do this explicitly.
-			//
-			// This converts a path like "./io/" to "io". Without this step, running
-			// "cd $GOROOT/src/pkg; go list ./io/..." would incorrectly skip the io
-			// package, because prepending the prefix "./" to the unclean path would
-			// result in "././io", and match("././io") returns false.
-			path = filepath.Clean(path)
-		}
+	wd, err := os.Getwd()
+	if err != nil {
+		return filename
+	}
 
-		// Avoid .foo, _foo, testdata and vendor directory trees, but do not avoid "." or "..".
-		_, elem := filepath.Split(path)
-		dot := strings.HasPrefix(elem, ".") && elem != "." && elem != ".."
-		if dot || strings.HasPrefix(elem, "_") || elem == "testdata" || elem == "vendor" {
-			return filepath.SkipDir
-		}
+	rel, err := filepath.Rel(wd, filename)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return filename
+	}
 
-		name := prefix + filepath.ToSlash(path)
-		if !match(name) {
-			return nil
-		}
-		if _, err = build.ImportDir(path, 0); err != nil {
-			if _, noGo := err.(*build.NoGoError); !noGo {
-				log.Print(err)
-			}
-			return nil
-		}
-		pkgs = append(pkgs, name)
-		return nil
-	})
-	return pkgs
+	return rel
+}
+
+func isDir(filename string) bool {
+	fi, err := os.Stat(filename)
+	return err == nil && fi.IsDir()
+}
+
+func exists(filename string) bool {
+	_, err := os.Stat(filename)
+	return err == nil
 }
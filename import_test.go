@@ -0,0 +1,88 @@
+package identypo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_parseInputModules(t *testing.T) {
+	// the workspace fixture uses go.work, a replace directive and import paths outside of GOPATH
+	defer inModule(t, "testdata/workspace")()
+
+	want := []string{
+		`dep/dep.go:4:6 "Recieve" should be Receive in Recieve [public API]`,
+		`app/app.go:6:6 "Propogate" should be Propagate in Propogate [public API]`,
+		`app/app.go:7:6 "Recieve" should be Receive in Recieve [public API]`,
+		`lib/lib.go:4:5 "inital" should be initial in inital`,
+	}
+
+	got, err := FindIdentifierTypos([]string{"./app/...", "./lib/...", "example.com/dep"}, Flags{})
+	if err != nil {
+		t.Fatalf("FindIdentifierTypos %v", err)
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("\ngot %v\nexp %v\n", got, want)
+	}
+	for i := range want {
+		if got[i].String() != want[i] {
+			t.Errorf("\ngot %v\nexp %v\n", got[i], want[i])
+		}
+	}
+}
+
+// inModule switches to dir and enables module mode for the go command, returning a function that restores
+// the working directory and environment.
+func inModule(t testing.TB, dir string) func() {
+	var restore []func()
+
+	for key, value := range map[string]string{"GO111MODULE": "on", "GOFLAGS": ""} {
+		key := key
+		old, ok := os.LookupEnv(key)
+		os.Setenv(key, value)
+		if ok {
+			restore = append(restore, func() { os.Setenv(key, old) })
+		} else {
+			restore = append(restore, func() { os.Unsetenv(key) })
+		}
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	restore = append(restore, func() { os.Chdir(wd) })
+
+	return func() {
+		for _, f := range restore {
+			f()
+		}
+	}
+}
+
+// fixture writes files, keyed by slash-separated names, to a temporary directory that's removed when the test
+// finishes. It returns the directory, along with a function writing (or replacing) a file in it as the test goes on.
+func fixture(tb testing.TB, files map[string]string) (string, func(name, src string)) {
+	tb.Helper()
+	dir := tb.TempDir()
+
+	write := func(name, src string) {
+		tb.Helper()
+		name = filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			tb.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(src), 0644); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	for name, src := range files {
+		write(name, src)
+	}
+
+	return dir, write
+}
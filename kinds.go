@@ -0,0 +1,239 @@
+package identypo
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+// Kinds of identifiers, as reported in Finding.Kind and selected with Flags.Kinds. The kind of a use is the kind
+// of the identifier it refers to.
+const (
+	KindFunc            = "func"             // functions, without a receiver
+	KindMethod          = "method"           // methods declared with a receiver
+	KindInterfaceMethod = "interface-method" // methods declared in an interface
+	KindVar             = "var"              // variables, including parameters and results
+	KindConst           = "const"            // constants
+	KindType            = "type"             // type declarations
+	KindField           = "field"            // struct fields
+	KindTypeParam       = "typeparam"        // type parameters
+	KindLabel           = "label"            // labels
+	KindPackage         = "package"          // package names, in package clauses and unaliased imports
+	KindImport          = "import"           // import aliases
+)
+
+// Kinds lists every kind of identifier that can be selected with Flags.Kinds.
+var Kinds = []string{KindFunc, KindMethod, KindInterfaceMethod, KindVar, KindConst, KindType, KindField, KindTypeParam, KindLabel, KindPackage, KindImport}
+
+// kindAliases are the other names accepted for kinds, including the names used before kinds were added.
+var kindAliases = map[string]string{
+	"function":          KindFunc,
+	"functions":         KindFunc,
+	"methods":           KindMethod,
+	"interface-methods": KindInterfaceMethod,
+	"variable":          KindVar,
+	"variables":         KindVar,
+	"constant":          KindConst,
+	"constants":         KindConst,
+	"types":             KindType,
+	"fields":            KindField,
+	"typeparams":        KindTypeParam,
+	"labels":            KindLabel,
+	"packages":          KindPackage,
+	"imports":           KindImport,
+}
+
+// kindSet returns the set of kinds named in kinds, or nil if kinds is empty (in which case every identifier is checked).
+func kindSet(kinds []string) (map[string]bool, error) {
+	if len(kinds) == 0 {
+		return nil, nil
+	}
+
+	set := make(map[string]bool, len(kinds))
+	for _, kind := range kinds {
+		name := strings.ToLower(strings.TrimSpace(kind))
+		if alias, ok := kindAliases[name]; ok {
+			name = alias
+		}
+
+		known := false
+		for _, k := range Kinds {
+			known = known || k == name
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown kind %q, must be one of %v", kind, strings.Join(Kinds, ", "))
+		}
+		set[name] = true
+	}
+
+	return set, nil
+}
+
+// declarationKinds records the kind of every declaring identifier in node, so identifiers can be classified
+// without type information. Uses are left to the type information, or the objects resolved by the parser.
+func declarationKinds(kinds map[*ast.Ident]string, node ast.Node) {
+	fields := func(list *ast.FieldList, kind string) {
+		if list == nil {
+			return
+		}
+		for _, field := range list.List {
+			for _, name := range field.Names {
+				kinds[name] = kind
+			}
+		}
+	}
+
+	switch n := node.(type) {
+	case *ast.File:
+		kinds[n.Name] = KindPackage
+	case *ast.ImportSpec:
+		if n.Name != nil {
+			kinds[n.Name] = KindImport
+		}
+	case *ast.GenDecl:
+		for _, spec := range n.Specs {
+			if vs, ok := spec.(*ast.ValueSpec); ok {
+				kind := KindVar
+				if n.Tok == token.CONST {
+					kind = KindConst
+				}
+				for _, name := range vs.Names {
+					kinds[name] = kind
+				}
+			}
+		}
+	case *ast.TypeSpec:
+		kinds[n.Name] = KindType
+		fields(n.TypeParams, KindTypeParam)
+	case *ast.FuncDecl:
+		if n.Recv == nil {
+			kinds[n.Name] = KindFunc
+			break
+		}
+		kinds[n.Name] = KindMethod
+		fields(n.Recv, KindVar)
+		// the type parameters of a generic receiver, as in func (l *List[T]) Len() int
+		for _, recv := range n.Recv.List {
+			typ := recv.Type
+			if star, ok := typ.(*ast.StarExpr); ok {
+				typ = star.X
+			}
+			var params []ast.Expr
+			switch t := typ.(type) {
+			case *ast.IndexExpr:
+				params = []ast.Expr{t.Index}
+			case *ast.IndexListExpr:
+				params = t.Indices
+			}
+			for _, param := range params {
+				if ident, ok := param.(*ast.Ident); ok {
+					kinds[ident] = KindTypeParam
+				}
+			}
+		}
+	case *ast.FuncType:
+		fields(n.TypeParams, KindTypeParam)
+		fields(n.Params, KindVar)
+		fields(n.Results, KindVar)
+	case *ast.StructType:
+		fields(n.Fields, KindField)
+	case *ast.InterfaceType:
+		fields(n.Methods, KindInterfaceMethod)
+	case *ast.AssignStmt:
+		if n.Tok == token.DEFINE {
+			for _, lhs := range n.Lhs {
+				if ident, ok := lhs.(*ast.Ident); ok {
+					kinds[ident] = KindVar
+				}
+			}
+		}
+	case *ast.RangeStmt:
+		if n.Tok == token.DEFINE {
+			for _, x := range []ast.Expr{n.Key, n.Value} {
+				if ident, ok := x.(*ast.Ident); ok {
+					kinds[ident] = KindVar
+				}
+			}
+		}
+	case *ast.LabeledStmt:
+		kinds[n.Label] = KindLabel
+	}
+}
+
+// kind returns the kind of ident, or an empty string if it can't be determined. Declarations are classified by
+// their syntax, while uses are classified by the object they refer to, using type information if it's available.
+func (c *checker) kind(ident *ast.Ident) string {
+	if kind, ok := c.declKinds[ident]; ok {
+		return kind
+	}
+
+	if c.info != nil {
+		if obj := c.info.Uses[ident]; obj != nil {
+			return objectKind(obj)
+		}
+		if obj := c.info.Defs[ident]; obj != nil {
+			return objectKind(obj)
+		}
+	}
+
+	if ident.Obj == nil {
+		return ""
+	}
+	switch ident.Obj.Kind {
+	case ast.Fun:
+		return KindFunc
+	case ast.Var:
+		return KindVar
+	case ast.Con:
+		return KindConst
+	case ast.Typ:
+		// the parser declares type parameters with the field list they're declared in
+		if _, ok := ident.Obj.Decl.(*ast.Field); ok {
+			return KindTypeParam
+		}
+		return KindType
+	case ast.Lbl:
+		return KindLabel
+	case ast.Pkg:
+		return KindPackage
+	}
+	return ""
+}
+
+// objectKind returns the kind of identifiers referring to obj.
+func objectKind(obj types.Object) string {
+	switch obj := obj.(type) {
+	case *types.Func:
+		if sig, ok := obj.Type().(*types.Signature); ok && sig.Recv() != nil {
+			if types.IsInterface(sig.Recv().Type()) {
+				return KindInterfaceMethod
+			}
+			return KindMethod
+		}
+		return KindFunc
+	case *types.Builtin:
+		return KindFunc
+	case *types.Var:
+		if obj.IsField() {
+			return KindField
+		}
+		return KindVar
+	case *types.Const:
+		return KindConst
+	case *types.TypeName:
+		if _, ok := obj.Type().(*types.TypeParam); ok {
+			return KindTypeParam
+		}
+		return KindType
+	case *types.Label:
+		return KindLabel
+	case *types.PkgName:
+		if obj.Name() != obj.Imported().Name() {
+			return KindImport
+		}
+		return KindPackage
+	}
+	return ""
+}
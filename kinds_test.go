@@ -0,0 +1,184 @@
+package identypo
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"testing"
+)
+
+// kindsSrc has a misspelled identifier of every kind.
+const kindsSrc = `package recieve
+
+import tennant "strings"
+
+type Succesful[Paramater any] struct {
+	Adress Paramater
+}
+
+type Reciever interface {
+	Recieve() string
+}
+
+func (s Succesful[Paramater]) Recieve() string {
+	_ = s.Adress
+	return tennant.ToUpper("")
+}
+
+func begining(occured int) int {
+authorithy:
+	for {
+		break authorithy
+	}
+	return occured + inital
+}
+
+const inital = 1
+`
+
+func Test_FindIdentifierTyposKinds(t *testing.T) {
+	dir, _ := fixture(t, map[string]string{
+		"go.mod":  "module example.com/recieve\n\ngo 1.18\n",
+		"file.go": kindsSrc,
+	})
+	defer inModule(t, dir)()
+
+	corrections := map[string]string{"tennant": "tenant", "paramater": "parameter"}
+
+	tests := []struct {
+		name  string
+		kinds []string
+		want  []string
+	}{
+		{name: "func",
+			kinds: []string{KindFunc},
+			want: []string{
+				"file.go:18:6 \"begining\" should be beginning in begining",
+			},
+		},
+		{name: "method",
+			kinds: []string{KindMethod},
+			want: []string{
+				"file.go:13:31 \"Recieve\" should be Receive in Recieve [public API]",
+			},
+		},
+		{name: "interface method",
+			kinds: []string{KindInterfaceMethod},
+			want: []string{
+				"file.go:10:2 \"Recieve\" should be Receive in Recieve [public API]",
+			},
+		},
+		{name: "var",
+			kinds: []string{KindVar},
+			want: []string{
+				"file.go:18:15 \"occured\" should be occurred in occured",
+				"file.go:23:9 \"occured\" should be occurred in occured",
+			},
+		},
+		{name: "const",
+			kinds: []string{KindConst},
+			want: []string{
+				"file.go:23:19 \"inital\" should be initial in inital",
+				"file.go:26:7 \"inital\" should be initial in inital",
+			},
+		},
+		{name: "type",
+			kinds: []string{KindType},
+			want: []string{
+				"file.go:5:6 \"Succesful\" should be Successful in Succesful [public API]",
+				"file.go:9:6 \"Reciever\" should be Receiver in Reciever [public API]",
+				"file.go:13:9 \"Succesful\" should be Successful in Succesful [public API]",
+			},
+		},
+		{name: "field",
+			kinds: []string{KindField},
+			want: []string{
+				"file.go:6:2 \"Adress\" should be Address in Adress [public API]",
+				"file.go:14:8 \"Adress\" should be Address in Adress [public API]",
+			},
+		},
+		{name: "type parameter",
+			kinds: []string{KindTypeParam},
+			want: []string{
+				"file.go:5:16 \"Paramater\" should be Parameter in Paramater",
+				"file.go:6:9 \"Paramater\" should be Parameter in Paramater",
+				"file.go:13:19 \"Paramater\" should be Parameter in Paramater",
+			},
+		},
+		{name: "label",
+			kinds: []string{KindLabel},
+			want: []string{
+				"file.go:19:1 \"authorithy\" should be authority in authorithy",
+				"file.go:21:9 \"authorithy\" should be authority in authorithy",
+			},
+		},
+		{name: "package",
+			kinds: []string{KindPackage},
+			want: []string{
+				"file.go:1:9 \"recieve\" should be receive in recieve",
+			},
+		},
+		{name: "import alias",
+			kinds: []string{KindImport},
+			want: []string{
+				"file.go:3:8 \"tennant\" should be tenant in tennant",
+				"file.go:15:9 \"tennant\" should be tenant in tennant",
+			},
+		},
+		{name: "combined kinds with aliases",
+			kinds: []string{"types", "Field"},
+			want: []string{
+				"file.go:5:6 \"Succesful\" should be Successful in Succesful [public API]",
+				"file.go:6:2 \"Adress\" should be Address in Adress [public API]",
+				"file.go:9:6 \"Reciever\" should be Receiver in Reciever [public API]",
+				"file.go:13:9 \"Succesful\" should be Successful in Succesful [public API]",
+				"file.go:14:8 \"Adress\" should be Address in Adress [public API]",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flags := Flags{Kinds: tt.kinds, Corrections: corrections}
+			findings, err := FindIdentifierTypos([]string{"."}, flags)
+			if err != nil {
+				t.Fatalf("FindIdentifierTypos %v", err)
+			}
+
+			var got, declarations []string
+			for _, f := range findings {
+				got = append(got, f.String())
+				if f.Declaration {
+					declarations = append(declarations, f.String())
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("\ngot %q\nexp %q\n", got, tt.want)
+			}
+
+			// declarations are classified the same way without type information
+			fset := token.NewFileSet()
+			f, err := parser.ParseFile(fset, "file.go", kindsSrc, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			flags, err = flags.prepare()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var untyped []string
+			for _, finding := range findTypos(fset, []*ast.File{f}, nil, flags) {
+				if finding.Declaration {
+					untyped = append(untyped, finding.String())
+				}
+			}
+			if !reflect.DeepEqual(untyped, declarations) {
+				t.Fatalf("without type information\ngot %q\nexp %q\n", untyped, declarations)
+			}
+		})
+	}
+
+	if _, err := FindIdentifierTypos([]string{"."}, Flags{Kinds: []string{"closure"}}); err == nil {
+		t.Fatalf("expected error for unknown kind")
+	}
+}
@@ -0,0 +1,270 @@
+package identypo
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// minLexiconWord is the length of the shortest word checked against the word lists, since shorter words are often
+// abbreviations (such as cfg or proc) that happen to be a letter away from a real word.
+const minLexiconWord = 5
+
+// DefaultEditDistance is the largest edit distance a word can be from a known word to be reported, used when
+// Flags.EditDistance is 0.
+const DefaultEditDistance = 2
+
+// maxSuggestions is the largest number of words suggested for a misspelling found with the word lists.
+const maxSuggestions = 3
+
+// LoadWordList reads a word list, such as /usr/share/dict/words or a hunspell .dic file, returning its words in lower
+// case. Each line holds a single word, and anything after a / (hunspell's affix flags) is ignored. Lines that aren't
+// a single word made of letters, such as comments, a hunspell word count, or words with apostrophes, are skipped.
+func LoadWordList(filename string) ([]string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		word := strings.TrimSpace(scanner.Text())
+		if i := strings.IndexByte(word, '/'); i >= 0 {
+			word = word[:i]
+		}
+		if word == "" || strings.IndexFunc(word, func(r rune) bool { return !unicode.IsLetter(r) }) >= 0 {
+			continue
+		}
+		words = append(words, strings.ToLower(word))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return words, nil
+}
+
+// loadWordLists returns a copy of flags with the words of the word lists in flags.WordLists loaded.
+func (flags Flags) loadWordLists() (Flags, error) {
+	flags.words = nil
+	for _, filename := range flags.WordLists {
+		words, err := LoadWordList(filename)
+		if err != nil {
+			return flags, err
+		}
+		flags.words = append(flags.words, words...)
+	}
+
+	return flags, nil
+}
+
+// lexicon finds typos that aren't in misspell's dictionary, such as Procesor or Transacton, by looking for the known
+// words closest to words it doesn't know. Known words are those of the word lists, along with programmingWords,
+// the corrections in misspell's dictionary and the acronyms identifiers are split on. Nothing is modified once
+// the lexicon is created, so it's safe for concurrent use.
+type lexicon struct {
+	words       map[string]bool
+	byLength    [][]string
+	maxDistance int
+}
+
+// newLexicon returns a lexicon of words and the other known words, for the misspell rules in replacements.
+func newLexicon(words, replacements, acronyms []string, maxDistance int) *lexicon {
+	l := &lexicon{
+		words:       make(map[string]bool, len(words)+len(programmingWords)+len(replacements)/2),
+		maxDistance: maxDistance,
+	}
+
+	for _, list := range [][]string{words, programmingWords, acronyms} {
+		for _, w := range list {
+			l.words[strings.ToLower(w)] = true
+		}
+	}
+	for i := 1; i < len(replacements); i += 2 {
+		for _, w := range correctionWords(replacements[i]) {
+			l.words[w] = true
+		}
+	}
+	for i := 0; i < len(replacements); i += 2 {
+		delete(l.words, replacements[i])
+	}
+
+	for w := range l.words {
+		for len(l.byLength) <= len(w) {
+			l.byLength = append(l.byLength, nil)
+		}
+		l.byLength[len(w)] = append(l.byLength[len(w)], w)
+	}
+	for _, ws := range l.byLength {
+		sort.Strings(ws)
+	}
+
+	return l
+}
+
+// known reports whether w is a known word, or an inflection of one.
+func (l *lexicon) known(w string) bool {
+	if l.words[w] {
+		return true
+	}
+	for _, suffix := range inflections {
+		if strings.HasSuffix(w, suffix) && l.words[strings.TrimSuffix(w, suffix)] {
+			return true
+		}
+	}
+	return false
+}
+
+// suggest returns the known words nearest to word, in the case of word, if word isn't known but is close enough to
+// a known word to be a typo of it. Words shorter than 8 letters must be a single edit from a known word, while longer
+// words may be up to the lexicon's maximum distance. Words shorter than minLexiconWord, and words with anything other
+// than ASCII letters, aren't checked.
+func (l *lexicon) suggest(word string) []string {
+	if len(word) < minLexiconWord || strings.IndexFunc(word, func(r rune) bool { return r > unicode.MaxASCII || !unicode.IsLetter(r) }) >= 0 {
+		return nil
+	}
+
+	lower := strings.ToLower(word)
+	if l.known(lower) {
+		return nil
+	}
+
+	maxDistance := l.maxDistance
+	if len(lower) < 8 && maxDistance > 1 {
+		maxDistance = 1
+	}
+
+	// nearest holds the known words best edits from word
+	var rows editRows
+	best := maxDistance
+	var nearest []string
+	for n := len(lower) - maxDistance; n <= len(lower)+maxDistance; n++ {
+		if n < 0 || n >= len(l.byLength) {
+			continue
+		}
+		for _, w := range l.byLength[n] {
+			d := rows.distance(lower, w, best)
+			if d > best {
+				continue
+			}
+			if d < best {
+				best, nearest = d, nearest[:0]
+			}
+			nearest = append(nearest, w)
+		}
+	}
+	if len(nearest) == 0 {
+		return nil
+	}
+
+	// prefer words that start the same way, since typos are rarely in the first letter
+	sort.Slice(nearest, func(i, j int) bool {
+		if si, sj := nearest[i][0] == lower[0], nearest[j][0] == lower[0]; si != sj {
+			return si
+		}
+		return nearest[i] < nearest[j]
+	})
+	if len(nearest) > maxSuggestions {
+		nearest = nearest[:maxSuggestions]
+	}
+
+	suggestions := make([]string, len(nearest))
+	for i, w := range nearest {
+		suggestions[i] = matchCase(word, w)
+	}
+	return suggestions
+}
+
+// matchCase returns w in the case of word: upper case, capitalized or lower case.
+func matchCase(word, w string) string {
+	switch {
+	case strings.ToUpper(word) == word:
+		return strings.ToUpper(w)
+	case unicode.IsUpper(rune(word[0])):
+		return strings.ToUpper(w[:1]) + w[1:]
+	}
+	return w
+}
+
+// editRows holds the rows of the edit distance table, reused between calls to distance.
+type editRows struct {
+	prev2, prev, cur []int
+}
+
+// distance returns the optimal string alignment distance between a and b (the number of letters inserted, deleted
+// or substituted, or adjacent letters transposed, to turn a into b), or anything larger than max if it's larger.
+func (r *editRows) distance(a, b string, max int) int {
+	if len(a)-len(b) > max || len(b)-len(a) > max {
+		return max + 1
+	}
+
+	for _, row := range []*[]int{&r.prev2, &r.prev, &r.cur} {
+		if cap(*row) < len(b)+1 {
+			*row = make([]int, len(b)+1)
+		}
+		*row = (*row)[:len(b)+1]
+	}
+	for j := range r.prev {
+		r.prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		r.cur[0] = i
+		rowMin := i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d := r.prev[j-1] + cost
+			if r.prev[j]+1 < d {
+				d = r.prev[j] + 1
+			}
+			if r.cur[j-1]+1 < d {
+				d = r.cur[j-1] + 1
+			}
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] && r.prev2[j-2]+1 < d {
+				d = r.prev2[j-2] + 1
+			}
+			r.cur[j] = d
+			if d < rowMin {
+				rowMin = d
+			}
+		}
+		// the distance can only grow from the smallest in the row
+		if rowMin > max {
+			return max + 1
+		}
+		r.prev2, r.prev, r.cur = r.prev, r.cur, r.prev2
+	}
+
+	return r.prev[len(b)]
+}
+
+// editDistance returns the largest edit distance of a typo from a known word, or an error if it's out of range.
+func editDistance(distance int) (int, error) {
+	if distance < 0 || distance > 2 {
+		return 0, fmt.Errorf("invalid edit distance %v, must be 1 or 2", distance)
+	}
+	if distance == 0 {
+		return DefaultEditDistance, nil
+	}
+	return distance, nil
+}
+
+// wordLists returns the word list settings of the checker's flags, which change the words checked, or nil if no
+// word lists are used.
+func (c *checker) wordLists() []string {
+	if len(c.flags.WordLists) == 0 {
+		return nil
+	}
+	distance, _ := editDistance(c.flags.EditDistance)
+	words := append([]string{"words", fmt.Sprint(distance)}, c.flags.words...)
+	sort.Strings(words[2:])
+	return words
+}
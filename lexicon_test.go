@@ -0,0 +1,175 @@
+package identypo
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"testing"
+)
+
+func Test_LoadWordList(t *testing.T) {
+	got, err := LoadWordList("testdata/words.txt")
+	if err != nil {
+		t.Fatalf("LoadWordList %v", err)
+	}
+
+	want := []string{"address", "cable", "label", "processor", "professor", "receive", "request", "table", "transaction"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("\ngot %q\nexp %q\n", got, want)
+	}
+
+	if _, err := LoadWordList("testdata/missing.txt"); err == nil {
+		t.Fatalf("expected error loading a missing word list")
+	}
+}
+
+func Test_editRowsDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		max  int
+		want int
+	}{
+		{a: "receive", b: "receive", max: 2, want: 0},
+		{a: "procesor", b: "processor", max: 2, want: 1},
+		{a: "recieve", b: "receive", max: 2, want: 1},
+		{a: "trnsactin", b: "transaction", max: 2, want: 2},
+		{a: "kitten", b: "sitting", max: 3, want: 3},
+
+		// anything larger than max is max+1
+		{a: "kitten", b: "sitting", max: 2, want: 3},
+		{a: "label", b: "transaction", max: 2, want: 3},
+		{a: "", b: "abc", max: 1, want: 2},
+	}
+	var rows editRows
+	for _, tt := range tests {
+		if got := rows.distance(tt.a, tt.b, tt.max); got != tt.want {
+			t.Errorf("distance(%q, %q, %d) = %d, exp %d", tt.a, tt.b, tt.max, got, tt.want)
+		}
+	}
+}
+
+func Test_lexiconSuggest(t *testing.T) {
+	words, err := LoadWordList("testdata/words.txt")
+	if err != nil {
+		t.Fatalf("LoadWordList %v", err)
+	}
+	c := newChecker(Flags{})
+
+	tests := []struct {
+		word        string
+		maxDistance int
+		want        []string
+	}{
+		{word: "Procesor", maxDistance: 2, want: []string{"Processor"}},
+		{word: "PROCESOR", maxDistance: 2, want: []string{"PROCESSOR"}},
+		{word: "reqest", maxDistance: 2, want: []string{"request"}},
+		{word: "transacton", maxDistance: 2, want: []string{"transaction"}},
+
+		// words as close as each other, starting with the same letter first
+		{word: "lable", maxDistance: 2, want: []string{"label", "cable", "table"}},
+
+		// two edits away, for long enough words only
+		{word: "trnsactin", maxDistance: 2, want: []string{"transaction"}},
+		{word: "trnsactin", maxDistance: 1, want: nil},
+		{word: "adrss", maxDistance: 2, want: nil},
+
+		// known words, their inflections and words known to misspell or as programming jargon
+		{word: "Processor", maxDistance: 2, want: nil},
+		{word: "labels", maxDistance: 2, want: nil},
+		{word: "existence", maxDistance: 2, want: nil},
+		{word: "goroutine", maxDistance: 2, want: nil},
+
+		// words that aren't checked, or aren't close to anything
+		{word: "lbl", maxDistance: 2, want: nil},
+		{word: "proc3sor", maxDistance: 2, want: nil},
+		{word: "xyzzyq", maxDistance: 2, want: nil},
+	}
+	for _, tt := range tests {
+		l := newLexicon(words, c.replacer.Replacements, c.acronyms, tt.maxDistance)
+		if got := l.suggest(tt.word); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("suggest(%q) with distance %d\ngot %q\nexp %q\n", tt.word, tt.maxDistance, got, tt.want)
+		}
+	}
+
+	for _, invalid := range []int{-1, 3} {
+		if _, err := (Flags{EditDistance: invalid}).prepare(); err == nil {
+			t.Errorf("expected error for edit distance %v", invalid)
+		}
+	}
+}
+
+func Test_verdictSuggestions(t *testing.T) {
+	for _, v := range []verdict{
+		{correction: "Processor", misspelled: true},
+		{correction: "label", alternatives: []string{"cable", "table"}, misspelled: true},
+	} {
+		if got := cachedVerdict(v.suggestions(), true); !reflect.DeepEqual(got, v) {
+			t.Errorf("\ngot %+v\nexp %+v\n", got, v)
+		}
+	}
+}
+
+func Test_findTyposWordLists(t *testing.T) {
+	src := `package main
+
+func NewProcesor(lable string) {}
+
+var reqestTimeout, goroutines, cfg = 0, 0, 0
+`
+	tests := []struct {
+		name  string
+		flags Flags
+		want  []string
+	}{
+		{name: "without word lists",
+			flags: Flags{},
+			want:  nil,
+		},
+		{name: "with word lists",
+			flags: Flags{WordLists: []string{"testdata/words.txt"}},
+			want: []string{
+				"file.go:3:9 \"Procesor\" should be Processor in NewProcesor",
+				"file.go:3:18 \"lable\" should be label (or cable or table) in lable",
+				"file.go:5:5 \"reqest\" should be request in reqestTimeout",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flags, err := tt.flags.prepare()
+			if err != nil {
+				t.Fatalf("prepare %v", err)
+			}
+
+			fset := token.NewFileSet()
+			f, err := parser.ParseFile(fset, "file.go", src, 0)
+			if err != nil {
+				t.Fatalf("Did not expect error parsing file, %v", err)
+			}
+
+			var got []string
+			for _, finding := range findTypos(fset, []*ast.File{f}, nil, flags) {
+				got = append(got, finding.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("\ngot %q\nexp %q\n", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_correctAmbiguous(t *testing.T) {
+	flags, err := Flags{WordLists: []string{"testdata/words.txt"}}.prepare()
+	if err != nil {
+		t.Fatalf("prepare %v", err)
+	}
+	c := newChecker(flags)
+
+	if got, err := c.correct("newProcesor"); err != nil || got != "newProcessor" {
+		t.Errorf("correct(newProcesor) = %q, %v, exp newProcessor", got, err)
+	}
+	if got, err := c.correct("lableText"); err == nil || got != "labelText" {
+		t.Errorf("correct(lableText) = %q, %v, exp labelText and an error", got, err)
+	}
+}
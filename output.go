@@ -0,0 +1,18 @@
+package identypo
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// WriteJSON writes findings to w as a single JSON array, with one object per finding.
+// An empty (or nil) slice of findings is written as an empty array.
+func WriteJSON(w io.Writer, findings []Finding) error {
+	if findings == nil {
+		findings = []Finding{}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(findings)
+}
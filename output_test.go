@@ -0,0 +1,128 @@
+package identypo
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_WriteJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		findings []Finding
+		want     string
+	}{
+		{name: "no findings",
+			findings: nil,
+			want:     "[]\n",
+		},
+		{name: "single finding",
+			findings: []Finding{
+				{Filename: "testdata/file.go", Line: 15, Column: 7, Word: "Succesful", Offset: 8, Length: 9, Correction: "Successful", Identifier: "constantSuccesful", Kind: "const", Declaration: true, Severity: "internal"},
+			},
+			want: `[
+  {
+    "file": "testdata/file.go",
+    "line": 15,
+    "column": 7,
+    "word": "Succesful",
+    "offset": 8,
+    "length": 9,
+    "suggestion": "Successful",
+    "identifier": "constantSuccesful",
+    "kind": "const",
+    "declaration": true,
+    "severity": "internal"
+  }
+]
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteJSON(&buf, tt.findings); err != nil {
+				t.Fatalf("WriteJSON %v", err)
+			}
+
+			if buf.String() != tt.want {
+				t.Fatalf("\ngot %v\nexp %v\n", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+var update = flag.Bool("update", false, "update golden files in testdata")
+
+func Test_WriteSARIF(t *testing.T) {
+	findings, err := FindIdentifierTypos([]string{"testdata/file.go"}, Flags{})
+	if err != nil {
+		t.Fatalf("FindIdentifierTypos %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteSARIF(&buf, findings); err != nil {
+		t.Fatalf("WriteSARIF %v", err)
+	}
+
+	golden := filepath.Join("testdata", "file.sarif.golden")
+	if *update {
+		if err := os.WriteFile(golden, buf.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if buf.String() != string(want) {
+		t.Fatalf("\ngot %v\nexp %v\n", buf.String(), string(want))
+	}
+}
+
+func Test_WriteSARIFColumns(t *testing.T) {
+	// columns are counted in code points, so the two bytes of é and è count once, whether they're before the
+	// identifier or within it
+	dir, _ := fixture(t, map[string]string{"file.go": "package file\n\nvar café, begining = 1, 2\n\nvar crèmeBegining = 3\n"})
+	filename := filepath.Join(dir, "file.go")
+
+	findings, err := FindIdentifierTypos([]string{filename}, Flags{})
+	if err != nil {
+		t.Fatalf("FindIdentifierTypos %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteSARIF(&buf, findings); err != nil {
+		t.Fatalf("WriteSARIF %v", err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatal(err)
+	}
+	if log.Runs[0].ColumnKind != "unicodeCodePoints" {
+		t.Fatalf("got columnKind %q", log.Runs[0].ColumnKind)
+	}
+
+	var got []sarifRegion
+	for _, result := range log.Runs[0].Results {
+		got = append(got, result.Locations[0].PhysicalLocation.Region)
+	}
+	want := []sarifRegion{
+		{StartLine: 3, StartColumn: 11, EndColumn: 19},
+		{StartLine: 5, StartColumn: 10, EndColumn: 18},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("\ngot %+v\nexp %+v\n", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("\ngot %+v\nexp %+v\n", got[i], want[i])
+		}
+	}
+}
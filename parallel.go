@@ -0,0 +1,46 @@
+package identypo
+
+import (
+	"runtime"
+	"sync"
+)
+
+// workers returns the number of files parsed, and identifiers checked, concurrently.
+func (flags Flags) workers() int {
+	if flags.Workers > 0 {
+		return flags.Workers
+	}
+	return runtime.NumCPU()
+}
+
+// parallel calls f for every index in [0, n) using at most workers goroutines, returning once every call has.
+// Callers write results to index i of a slice they own, so results stay in order however the calls are scheduled.
+func parallel(n, workers int, f func(i int)) {
+	if workers > n {
+		workers = n
+	}
+	if workers <= 1 {
+		for i := 0; i < n; i++ {
+			f(i)
+		}
+		return
+	}
+
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				f(i)
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+}
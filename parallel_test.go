@@ -0,0 +1,102 @@
+package identypo
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func Test_parallel(t *testing.T) {
+	for _, workers := range []int{0, 1, 3, 100} {
+		var calls int32
+		got := make([]int, 50)
+		parallel(len(got), workers, func(i int) {
+			atomic.AddInt32(&calls, 1)
+			got[i] = i * i
+		})
+
+		if calls != 50 {
+			t.Fatalf("workers %v: %v calls, exp 50", workers, calls)
+		}
+		for i, v := range got {
+			if v != i*i {
+				t.Fatalf("workers %v: got[%v] = %v, exp %v", workers, i, v, i*i)
+			}
+		}
+	}
+}
+
+func Test_FindIdentifierTyposWorkers(t *testing.T) {
+	dir := generatePackages(t, 8, 4, 1)
+	defer inModule(t, dir)()
+
+	want, err := FindIdentifierTypos([]string{"./..."}, Flags{Workers: 1, GroupByDeclaration: true})
+	if err != nil {
+		t.Fatalf("FindIdentifierTypos %v", err)
+	}
+	if len(want) != 8*4*2 {
+		t.Fatalf("expected 2 findings per generated file, got %v", len(want))
+	}
+
+	for _, workers := range []int{2, 8, 0} {
+		got, err := FindIdentifierTypos([]string{"./..."}, Flags{Workers: workers, GroupByDeclaration: true})
+		if err != nil {
+			t.Fatalf("FindIdentifierTypos %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("workers %v\ngot %v\nexp %v\n", workers, got, want)
+		}
+	}
+}
+
+func BenchmarkFindIdentifierTypos(b *testing.B) {
+	dir := generatePackages(b, 100, 10, 1)
+	defer inModule(b, dir)()
+
+	counts := []int{1}
+	if runtime.NumCPU() > 1 {
+		counts = append(counts, runtime.NumCPU())
+	}
+
+	for _, workers := range counts {
+		b.Run(fmt.Sprintf("j=%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := FindIdentifierTypos([]string{"./..."}, Flags{Workers: workers}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// generatePackages writes a module of packages and returns its directory, which is removed when the test finishes.
+// Every typoEvery'th file declares a misspelled function and a misspelled variable among correctly spelled ones,
+// while the rest are spelled correctly.
+func generatePackages(tb testing.TB, packages, filesPerPackage, typoEvery int) string {
+	dir, write := fixture(tb, map[string]string{"go.mod": "module example.com/generated\n"})
+	for p := 0; p < packages; p++ {
+		pkg := fmt.Sprintf("pkg%d", p)
+		for f := 0; f < filesPerPackage; f++ {
+			received, beginning := "received", "beginning"
+			if (p*filesPerPackage+f)%typoEvery == 0 {
+				received, beginning = "recieved", "begining"
+			}
+
+			var src strings.Builder
+			fmt.Fprintf(&src, "package %v\n\n", pkg)
+			fmt.Fprintf(&src, "var %vCount%d int\n\n", received, f)
+			fmt.Fprintf(&src, "func processRequest%d(requestBody string, responseWriter []byte) (bytesWritten int) {\n", f)
+			src.WriteString("\tfor index, character := range requestBody {\n")
+			src.WriteString("\t\tif index < len(responseWriter) && character != 0 {\n\t\t\tbytesWritten++\n\t\t}\n\t}\n")
+			fmt.Fprintf(&src, "\t%vCount%d += bytesWritten\n", received, f)
+			src.WriteString("\treturn bytesWritten\n}\n\n")
+			fmt.Fprintf(&src, "func %v%d() int { return processRequest%d(\"\", nil) }\n", beginning, f, f)
+			write(fmt.Sprintf("%v/file%d.go", pkg, f), src.String())
+		}
+	}
+
+	return dir
+}
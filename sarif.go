@@ -0,0 +1,285 @@
+package identypo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+
+	ruleMisspelledDeclaration = "misspelled-declaration"
+	ruleMisspelledUse         = "misspelled-use"
+	ruleUnusedSuppression     = "unused-suppression"
+	ruleInconsistentSpelling  = "inconsistent-spelling"
+)
+
+// sarifRules are the rules reported by identypo, one per finding category. Results refer to these by index.
+var sarifRules = []sarifRule{
+	{
+		ID:               ruleMisspelledDeclaration,
+		Name:             "MisspelledDeclaration",
+		ShortDescription: sarifMessage{Text: "Misspelled identifier declaration"},
+		FullDescription:  sarifMessage{Text: "A declared identifier (function, variable, constant, type, label, etc.) contains a misspelled word."},
+	},
+	{
+		ID:               ruleMisspelledUse,
+		Name:             "MisspelledUse",
+		ShortDescription: sarifMessage{Text: "Misspelled identifier use"},
+		FullDescription:  sarifMessage{Text: "A reference to an identifier contains a misspelled word. The misspelling originates in the declaration of the identifier, which may be in another package."},
+	},
+	{
+		ID:               ruleUnusedSuppression,
+		Name:             "UnusedSuppression",
+		ShortDescription: sarifMessage{Text: "Unused suppression comment"},
+		FullDescription:  sarifMessage{Text: "An //identypo:ignore or //nolint:identypo comment does not suppress any misspelled identifiers."},
+	},
+	{
+		ID:               ruleInconsistentSpelling,
+		Name:             "InconsistentSpelling",
+		ShortDescription: sarifMessage{Text: "Inconsistently spelled identifier"},
+		FullDescription:  sarifMessage{Text: "An identifier contains a word that is spelled differently in most other identifiers, such as Canceled where Cancelled is used elsewhere."},
+	},
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool       sarifTool     `json:"tool"`
+	ColumnKind string        `json:"columnKind"`
+	Results    []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	Name             string       `json:"name"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+	FullDescription  sarifMessage `json:"fullDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID           string          `json:"ruleId"`
+	RuleIndex        int             `json:"ruleIndex"`
+	Level            string          `json:"level"`
+	Message          sarifMessage    `json:"message"`
+	Locations        []sarifLocation `json:"locations"`
+	RelatedLocations []sarifLocation `json:"relatedLocations,omitempty"`
+	Fixes            []sarifFix      `json:"fixes,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	Message          *sarifMessage         `json:"message,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+	EndColumn   int `json:"endColumn"`
+}
+
+type sarifFix struct {
+	Description     sarifMessage          `json:"description"`
+	ArtifactChanges []sarifArtifactChange `json:"artifactChanges"`
+}
+
+type sarifArtifactChange struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Replacements     []sarifReplacement    `json:"replacements"`
+}
+
+type sarifReplacement struct {
+	DeletedRegion   sarifRegion  `json:"deletedRegion"`
+	InsertedContent sarifMessage `json:"insertedContent"`
+}
+
+// WriteSARIF writes findings to w as a SARIF 2.1.0 log. Each finding is reported under the misspelled-declaration
+// or misspelled-use rule (or the inconsistent-spelling rule, for inconsistent spellings), with its correction and any
+// alternatives attached as fixes. Unused suppression comments are reported under the unused-suppression rule.
+// References of grouped findings are reported as related locations.
+// Regions cover just the misspelled word within each identifier. Columns are counted in Unicode code points, as
+// declared by the run's columnKind, rather than the bytes of go/token, so the source files are read to convert them.
+func WriteSARIF(w io.Writer, findings []Finding) error {
+	results := make([]sarifResult, 0, len(findings))
+	columns := make(sarifColumns)
+
+	for _, f := range findings {
+		if f.Kind == KindUnusedSuppression {
+			results = append(results, sarifResult{
+				RuleID:    ruleUnusedSuppression,
+				RuleIndex: 2,
+				Level:     "note",
+				Message:   sarifMessage{Text: fmt.Sprintf("unused suppression %v", f.Word)},
+				Locations: []sarifLocation{{
+					PhysicalLocation: sarifPhysicalLocation{
+						ArtifactLocation: sarifArtifact(f.Filename),
+						Region: sarifRegion{
+							StartLine:   f.Line,
+							StartColumn: columns.column(f.Filename, f.Line, f.Column),
+							EndColumn:   columns.column(f.Filename, f.Line, f.Column) + utf8.RuneCountInString(f.Word),
+						},
+					},
+				}},
+			})
+			continue
+		}
+
+		ruleIndex := 1
+		switch {
+		case f.Inconsistent:
+			ruleIndex = 3
+		case f.Declaration:
+			ruleIndex = 0
+		}
+
+		location := sarifArtifact(f.Filename)
+		// the word's offset and length within the identifier are in bytes too
+		offset, length := f.Offset, f.Length
+		if f.Offset+f.Length <= len(f.Identifier) {
+			offset = utf8.RuneCountInString(f.Identifier[:f.Offset])
+			length = utf8.RuneCountInString(f.Identifier[f.Offset : f.Offset+f.Length])
+		}
+
+		start := columns.column(f.Filename, f.Line, f.Column)
+		region := sarifRegion{
+			StartLine:   f.Line,
+			StartColumn: start + offset,
+			EndColumn:   start + offset + length,
+		}
+
+		var related []sarifLocation
+		for _, ref := range f.References {
+			refStart := columns.column(ref.Filename, ref.Line, ref.Column)
+			related = append(related, sarifLocation{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifact(ref.Filename),
+					Region: sarifRegion{
+						StartLine:   ref.Line,
+						StartColumn: refStart + offset,
+						EndColumn:   refStart + offset + length,
+					},
+				},
+				Message: &sarifMessage{Text: fmt.Sprintf("%v referenced here", f.Identifier)},
+			})
+		}
+
+		// typos in the public API are errors, since fixing them is a breaking change
+		level := "warning"
+		if f.Severity == SeverityPublic {
+			level = "error"
+		}
+
+		// each suggestion is a fix of its own, the correction first
+		var fixes []sarifFix
+		for _, suggestion := range append([]string{f.Correction}, f.Alternatives...) {
+			fixes = append(fixes, sarifFix{
+				Description: sarifMessage{Text: fmt.Sprintf("Replace %v with %v", f.Word, suggestion)},
+				ArtifactChanges: []sarifArtifactChange{{
+					ArtifactLocation: location,
+					Replacements: []sarifReplacement{{
+						DeletedRegion:   region,
+						InsertedContent: sarifMessage{Text: suggestion},
+					}},
+				}},
+			})
+		}
+
+		results = append(results, sarifResult{
+			RuleID:    sarifRules[ruleIndex].ID,
+			RuleIndex: ruleIndex,
+			Level:     level,
+			Message:   sarifMessage{Text: f.message()},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: location,
+					Region:           region,
+				},
+			}},
+			RelatedLocations: related,
+			Fixes:            fixes,
+		})
+	}
+
+	log := sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{{
+			Tool: sarifTool{
+				Driver: sarifDriver{
+					Name:           "identypo",
+					InformationURI: "https://github.com/alexkohler/identypo",
+					Rules:          sarifRules,
+				},
+			},
+			ColumnKind: "unicodeCodePoints",
+			Results:    results,
+		}},
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(log)
+}
+
+// sarifArtifact returns the artifact location of filename, relative to the source root unless filename is absolute.
+func sarifArtifact(filename string) sarifArtifactLocation {
+	if filepath.IsAbs(filename) {
+		return sarifArtifactLocation{URI: "file://" + filepath.ToSlash(filename)}
+	}
+	return sarifArtifactLocation{URI: filepath.ToSlash(filename), URIBaseID: "%SRCROOT%"}
+}
+
+// sarifColumns holds the lines of each source file, keyed by file name, to convert the byte based columns of
+// go/token into the code point based columns of SARIF.
+type sarifColumns map[string][]string
+
+// column returns the code point based column of the byte based column on line of filename. Columns in files that
+// can't be read are returned as is.
+func (c sarifColumns) column(filename string, line, column int) int {
+	lines, ok := c[filename]
+	if !ok {
+		if src, err := os.ReadFile(filename); err == nil {
+			lines = strings.Split(string(src), "\n")
+		}
+		c[filename] = lines
+	}
+
+	if line < 1 || line > len(lines) || column < 1 || column-1 > len(lines[line-1]) {
+		return column
+	}
+	return utf8.RuneCountInString(lines[line-1][:column-1]) + 1
+}
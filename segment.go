@@ -0,0 +1,154 @@
+package identypo
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultSegmentConfidence is the confidence a split of run together words needs for its misspellings to be reported,
+// used when Flags.SegmentConfidence is 0.
+const DefaultSegmentConfidence = 0.5
+
+// minSegmentWord and maxSegmentWord bound the length of the words a run of letters is split into, while shorter
+// misspellings than minSegmentMisspelling (such as ect, in direct) are too easily found inside real words.
+const (
+	minSegmentWord        = 2
+	maxSegmentWord        = 30
+	minSegmentMisspelling = 4
+)
+
+// inflections are the suffixes a known word may be inflected with, as in opened or recorders.
+var inflections = []string{"s", "es", "ed", "d", "ing", "er", "ers", "ly"}
+
+// segmenter splits words of run together lower case letters, such as recieveddata, into known words and misspellings.
+// words are the known words, the corrections in misspell's dictionary along with programmingWords, while misspellings
+// are the misspellings it corrects. Neither is modified once the segmenter is created, so it's safe for concurrent use.
+type segmenter struct {
+	words        map[string]bool
+	misspellings map[string]bool
+	confidence   float64
+}
+
+// newSegmenter returns a segmenter for the misspell rules in replacements, reporting splits with at least confidence.
+func newSegmenter(replacements []string, confidence float64) *segmenter {
+	s := &segmenter{
+		words:        make(map[string]bool),
+		misspellings: make(map[string]bool, len(replacements)/2),
+		confidence:   confidence,
+	}
+
+	for i := 0; i+1 < len(replacements); i += 2 {
+		if len(replacements[i]) >= minSegmentMisspelling {
+			s.misspellings[replacements[i]] = true
+		}
+		for _, w := range correctionWords(replacements[i+1]) {
+			s.words[w] = true
+		}
+	}
+	for _, w := range programmingWords {
+		s.words[w] = true
+	}
+
+	// a locale's rules correct words that are otherwise known, such as color
+	for i := 0; i < len(replacements); i += 2 {
+		delete(s.words, replacements[i])
+	}
+
+	return s
+}
+
+// known reports whether w is a known word, or an inflection of one.
+func (s *segmenter) known(w string) bool {
+	if s.words[w] {
+		return true
+	}
+	for _, suffix := range inflections {
+		if strings.HasSuffix(w, suffix) && s.words[strings.TrimSuffix(w, suffix)] {
+			return true
+		}
+	}
+	return false
+}
+
+// segment returns the misspellings in the most confident split of text into known words and misspellings, with
+// offsets relative to text. text must be letters, all lower case apart from an optional capital first letter, as
+// in the Recieveddata of getRecieveddata. Nothing is returned if text can be split into known words alone, if it
+// can't be split at all, or if the confidence of the split is below the segmenter's threshold.
+//
+// The confidence of a split is the sum of the squared lengths of its words over the squared length of text, so a
+// split into a few long words (recieved and data, 0.56) is more confident than one into many short words, which
+// real words are easily mistaken for (in, for, mat and ion, 0.26).
+func (s *segmenter) segment(text string) []word {
+	if !isLowerRun(text) {
+		return nil
+	}
+	lower := strings.ToLower(text)
+	n := len(lower)
+
+	// clean[i] is whether lower[:i] can be split into known words alone, while score[i] is the highest sum of
+	// squared word lengths of a split of lower[:i] into known words and misspellings (-1 if there isn't one),
+	// with the last word of that split starting at start[i]
+	clean := make([]bool, n+1)
+	score := make([]int, n+1)
+	start := make([]int, n+1)
+	clean[0] = true
+	for i := 1; i <= n; i++ {
+		score[i] = -1
+		for j := i - minSegmentWord; j >= 0 && i-j <= maxSegmentWord; j-- {
+			w := lower[j:i]
+			known := !s.misspellings[w] && s.known(w)
+			if known && clean[j] {
+				clean[i] = true
+			}
+			if score[j] < 0 || (!known && !s.misspellings[w]) {
+				continue
+			}
+			if sum := score[j] + (i-j)*(i-j); sum > score[i] {
+				score[i], start[i] = sum, j
+			}
+		}
+	}
+
+	if clean[n] || score[n] < 0 || float64(score[n]) < s.confidence*float64(n*n) {
+		return nil
+	}
+
+	var misspelled []word
+	for i := n; i > 0; i = start[i] {
+		if w := lower[start[i]:i]; s.misspellings[w] {
+			misspelled = append([]word{{text: text[start[i]:i], offset: start[i]}}, misspelled...)
+		}
+	}
+	return misspelled
+}
+
+// isLowerRun reports whether s is ASCII letters, all lower case apart from an optional capital first letter.
+func isLowerRun(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if (s[i] < 'a' || s[i] > 'z') && (i > 0 || s[i] < 'A' || s[i] > 'Z') {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// segmentConfidence returns the confidence run together words are split with, or an error if it's out of range.
+func segmentConfidence(confidence float64) (float64, error) {
+	if confidence < 0 || confidence > 1 {
+		return 0, fmt.Errorf("invalid segment confidence %v, must be between 0 and 1", confidence)
+	}
+	if confidence == 0 {
+		return DefaultSegmentConfidence, nil
+	}
+	return confidence, nil
+}
+
+// segmentation returns the segmentation settings of the checker's flags, which change the words checked, or nil if
+// run together words aren't split.
+func (c *checker) segmentation() []string {
+	if !c.flags.Segment {
+		return nil
+	}
+	confidence, _ := segmentConfidence(c.flags.SegmentConfidence)
+	return []string{"segment", fmt.Sprint(confidence)}
+}
@@ -0,0 +1,120 @@
+package identypo
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"testing"
+)
+
+func Test_segmenterSegment(t *testing.T) {
+	c := newChecker(Flags{})
+	s := newSegmenter(c.replacer.Replacements, DefaultSegmentConfidence)
+
+	tests := []struct {
+		text string
+		want []word
+	}{
+		{text: "recieveddata", want: []word{{"recieved", 0}}},
+		{text: "usernmae", want: []word{{"nmae", 4}}},
+		{text: "Recieveddata", want: []word{{"Recieved", 0}}},
+		{text: "adressbook", want: []word{{"adress", 0}}},
+		{text: "defualtvalue", want: []word{{"defualt", 0}}},
+
+		// known words alone
+		{text: "numofitems", want: nil},
+		{text: "userdata", want: nil},
+		{text: "opened", want: nil},
+		{text: "recorded", want: nil},
+
+		// words that can't be split into known words are left to misspell
+		{text: "direct", want: nil},
+		{text: "xyzzy", want: nil},
+
+		// misspellings too short to be found inside other words
+		{text: "tehdata", want: nil},
+
+		// splits below the confidence threshold, 0.37 for new, defualt and value
+		{text: "newdefualtvalue", want: nil},
+
+		// not a lower case run
+		{text: "recievedData", want: nil},
+		{text: "recieved2data", want: nil},
+		{text: "", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := s.segment(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("segment(%q)\ngot %v\nexp %v\n", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_segmenterConfidence(t *testing.T) {
+	c := newChecker(Flags{})
+
+	// recieved and data, with a confidence of (8*8 + 4*4) / (12*12) = 0.56
+	for confidence, want := range map[float64]int{0.5: 1, 0.55: 1, 0.6: 0, 1: 0} {
+		s := newSegmenter(c.replacer.Replacements, confidence)
+		if got := s.segment("recieveddata"); len(got) != want {
+			t.Errorf("confidence %v: got %v, exp %d misspellings", confidence, got, want)
+		}
+	}
+
+	for _, invalid := range []float64{-0.1, 1.5} {
+		if _, err := (Flags{SegmentConfidence: invalid}).prepare(); err == nil {
+			t.Errorf("expected error for segment confidence %v", invalid)
+		}
+	}
+}
+
+func Test_findTyposSegment(t *testing.T) {
+	src := `package main
+
+func loadRecieveddata(usernmae string) {}
+
+var direct, opened = 0, 0
+`
+	tests := []struct {
+		name  string
+		flags Flags
+		want  []string
+	}{
+		{name: "without segmentation",
+			flags: Flags{},
+			want:  nil,
+		},
+		{name: "with segmentation",
+			flags: Flags{Segment: true},
+			want: []string{
+				"file.go:3:10 \"Recieved\" should be Received in loadRecieveddata",
+				"file.go:3:27 \"nmae\" should be name in usernmae",
+			},
+		},
+		{name: "with a higher confidence",
+			flags: Flags{Segment: true, SegmentConfidence: 0.55},
+			want: []string{
+				"file.go:3:10 \"Recieved\" should be Received in loadRecieveddata",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fset := token.NewFileSet()
+			f, err := parser.ParseFile(fset, "file.go", src, 0)
+			if err != nil {
+				t.Fatalf("Did not expect error parsing file, %v", err)
+			}
+
+			var got []string
+			for _, finding := range findTypos(fset, []*ast.File{f}, nil, tt.flags) {
+				got = append(got, finding.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("\ngot %q\nexp %q\n", got, tt.want)
+			}
+		})
+	}
+}
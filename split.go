@@ -0,0 +1,163 @@
+package identypo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Initialisms are the acronyms recognized when splitting identifiers into words, along with those in Flags.Acronyms.
+// They're the common initialisms golint expects to be consistently cased.
+var Initialisms = []string{
+	"ACL", "API", "ASCII", "CPU", "CSS", "DNS", "EOF", "GUID", "HTML", "HTTP", "HTTPS", "ID", "IP", "JSON", "LHS",
+	"QPS", "RAM", "RHS", "RPC", "SLA", "SMTP", "SQL", "SSH", "TCP", "TLS", "TTL", "UDP", "UI", "UID", "UUID", "URI",
+	"URL", "UTF8", "VM", "XML", "XMPP", "XSRF", "XSS",
+}
+
+// acronymList returns Initialisms along with the acronyms in extra, longest first so the longest acronym at any
+// position is matched (HTTPS rather than HTTP).
+func acronymList(extra []string) ([]string, error) {
+	acronyms := append([]string(nil), Initialisms...)
+	for _, a := range extra {
+		a = strings.TrimSpace(a)
+		if a == "" || strings.IndexFunc(a, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) >= 0 {
+			return nil, fmt.Errorf("invalid acronym %q, must be letters and digits", a)
+		}
+		acronyms = append(acronyms, a)
+	}
+
+	sort.SliceStable(acronyms, func(i, j int) bool { return len(acronyms[i]) > len(acronyms[j]) })
+	return acronyms, nil
+}
+
+// word is a word of an identifier, offset bytes from the start of the identifier.
+type word struct {
+	text   string
+	offset int
+}
+
+// splitIdentifier returns the words of name that are checked for typos. Words are separated by underscores (as in
+// snake_case and SCREAMING_SNAKE_CASE identifiers), which are left out, and by changes of case: a run of upper case
+// letters is a single word, apart from its last letter if that starts a capitalized word (HTTPServer is HTTP and
+// Server). Digits are words of their own (base64Encode is base, 64 and Encode), since misspell doesn't find
+// misspellings with digits attached.
+//
+// acronyms (see acronymList) are matched wherever a word starts, either in the case they're listed in or in upper
+// case, or also in lower case at the start of the identifier (or of a snake_case segment). This keeps mixed case
+// acronyms such as IPv6 or gRPC whole. An acronym is only split off if another word starts right after it, so
+// IDLE isn't split into ID and LE, and may be pluralized with a lower case s, as in URLs or IDsToFetch.
+func splitIdentifier(name string, acronyms []string) []word {
+	var words []word
+
+	offset := 0
+	for _, segment := range strings.Split(name, "_") {
+		for i := 0; i < len(segment); {
+			n := acronymAt(segment, i, acronyms)
+			if n == 0 {
+				n = wordAt(segment, i)
+			}
+			words = append(words, word{text: segment[i : i+n], offset: offset + i})
+			i += n
+		}
+		offset += len(segment) + len("_")
+	}
+
+	return words
+}
+
+// acronymAt returns the length of the acronym (including any plural s) at s[i:], or 0 if there isn't one.
+func acronymAt(s string, i int, acronyms []string) int {
+	for _, a := range acronyms {
+		end := i + len(a)
+		if end > len(s) {
+			continue
+		}
+
+		candidate := s[i:end]
+		if candidate != a && candidate != strings.ToUpper(a) && (i > 0 || candidate != strings.ToLower(a)) {
+			continue
+		}
+
+		if end < len(s) && s[end] == 's' && wordStarts(s, end+1, acronyms) {
+			return end + 1 - i
+		}
+		if wordStarts(s, end, acronyms) {
+			return end - i
+		}
+	}
+	return 0
+}
+
+// wordStarts reports whether a word ends at s[:i], because s[i:] is empty or starts a new word: anything but
+// a lower case letter, apart from an upper case letter that's part of a run of upper case letters (which only
+// starts a word if it's an acronym).
+func wordStarts(s string, i int, acronyms []string) bool {
+	if i == len(s) {
+		return true
+	}
+
+	r, size := utf8.DecodeRuneInString(s[i:])
+	if !unicode.IsUpper(r) {
+		return !unicode.IsLower(r)
+	}
+
+	next, _ := utf8.DecodeRuneInString(s[i+size:])
+	return unicode.IsLower(next) || acronymAt(s, i, acronyms) > 0
+}
+
+// wordAt returns the length of the word at s[i:], by case alone.
+func wordAt(s string, i int) int {
+	r, size := utf8.DecodeRuneInString(s[i:])
+
+	switch {
+	case unicode.IsUpper(r):
+		end := runEnd(s, i, unicode.IsUpper)
+		next, _ := utf8.DecodeRuneInString(s[end:])
+		if end == len(s) || !unicode.IsLower(next) {
+			return end - i
+		}
+		if end == i+size {
+			// a capitalized word, such as Server
+			return runEnd(s, end, unicode.IsLower) - i
+		}
+		// the last letter of the run starts the next word
+		_, last := utf8.DecodeLastRuneInString(s[:end])
+		return end - last - i
+	case unicode.IsLower(r):
+		return runEnd(s, i, unicode.IsLower) - i
+	case unicode.IsDigit(r):
+		return runEnd(s, i, unicode.IsDigit) - i
+	}
+
+	return runEnd(s, i, func(r rune) bool { return !unicode.IsUpper(r) && !unicode.IsLower(r) && !unicode.IsDigit(r) }) - i
+}
+
+// runEnd returns the end of the run of runes in s starting at i for which in is true.
+func runEnd(s string, i int, in func(rune) bool) int {
+	for i < len(s) {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if !in(r) {
+			break
+		}
+		i += size
+	}
+	return i
+}
+
+// isSnakeCase reports whether name separates its words with underscores, ignoring any leading or trailing underscores
+// (as in _recieve or RECIEVE_).
+func isSnakeCase(name string) bool {
+	return strings.Contains(strings.Trim(name, "_"), "_")
+}
+
+// joinCorrection converts a correction for a word of name into the case used by name. Hyphenated corrections are
+// joined with underscores in snake_case identifiers and upper case words (as misspell preserves the case of the
+// word it corrects, joining ALL-TIME in camelCase would lose the hyphen), and in camelCase otherwise.
+func joinCorrection(name, correction string) string {
+	if isSnakeCase(name) || strings.IndexFunc(correction, unicode.IsLower) < 0 {
+		return strings.Replace(correction, "-", "_", -1)
+	}
+	return hyphenToCamelCase(correction)
+}
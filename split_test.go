@@ -0,0 +1,113 @@
+package identypo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_splitIdentifier(t *testing.T) {
+	tests := []struct {
+		name     string
+		acronyms []string
+		want     []word
+	}{
+		{name: "recieveData", want: []word{{"recieve", 0}, {"Data", 7}}},
+		{name: "max_recieve_attempts", want: []word{{"max", 0}, {"recieve", 4}, {"attempts", 12}}},
+		{name: "DEFAULT_INITAL_TIMEOUT", want: []word{{"DEFAULT", 0}, {"INITAL", 8}, {"TIMEOUT", 15}}},
+		{name: "_retreive", want: []word{{"retreive", 1}}},
+		{name: "ALLTIME_", want: []word{{"ALLTIME", 0}}},
+		{name: "a__b", want: []word{{"a", 0}, {"b", 3}}},
+		{name: "HTTP_statusOk", want: []word{{"HTTP", 0}, {"status", 5}, {"Ok", 11}}},
+		{name: "_", want: nil},
+
+		// acronyms
+		{name: "HTTPSServerURLs", want: []word{{"HTTPS", 0}, {"Server", 5}, {"URLs", 11}}},
+		{name: "HTTPServer", want: []word{{"HTTP", 0}, {"Server", 4}}},
+		{name: "JSONMarshaller", want: []word{{"JSON", 0}, {"Marshaller", 4}}},
+		{name: "JSONAPIClient", want: []word{{"JSON", 0}, {"API", 4}, {"Client", 7}}},
+		{name: "IDsToFetch", want: []word{{"IDs", 0}, {"To", 3}, {"Fetch", 5}}},
+		{name: "userIDs", want: []word{{"user", 0}, {"IDs", 4}}},
+		{name: "ItemIDIs", want: []word{{"Item", 0}, {"ID", 4}, {"Is", 6}}},
+		{name: "httpClient", want: []word{{"http", 0}, {"Client", 4}}},
+		{name: "utf8String", want: []word{{"utf8", 0}, {"String", 4}}},
+		{name: "IDLE_TIMEOUT", want: []word{{"IDLE", 0}, {"TIMEOUT", 5}}},
+		{name: "identity", want: []word{{"identity", 0}}},
+		{name: "Ipsum", want: []word{{"Ipsum", 0}}},
+		{name: "IsValid", want: []word{{"Is", 0}, {"Valid", 2}}},
+		{name: "MAXHTTPRequest", want: []word{{"MAXHTTP", 0}, {"Request", 7}}},
+		{name: "gRPCClient", want: []word{{"g", 0}, {"RPC", 1}, {"Client", 4}}},
+		{name: "gRPCClient", acronyms: []string{"gRPC"}, want: []word{{"gRPC", 0}, {"Client", 4}}},
+		{name: "GRPC_PORT", acronyms: []string{"gRPC"}, want: []word{{"GRPC", 0}, {"PORT", 5}}},
+		{name: "IPv6Adress", want: []word{{"I", 0}, {"Pv", 1}, {"6", 3}, {"Adress", 4}}},
+		{name: "IPv6Adress", acronyms: []string{"IPv6"}, want: []word{{"IPv6", 0}, {"Adress", 4}}},
+		{name: "ipv6Adress", acronyms: []string{"IPv6"}, want: []word{{"ipv6", 0}, {"Adress", 4}}},
+		{name: "OAuthTokens", acronyms: []string{"OAuth"}, want: []word{{"OAuth", 0}, {"Tokens", 5}}},
+
+		// digits
+		{name: "base64Encdoe", want: []word{{"base", 0}, {"64", 4}, {"Encdoe", 6}}},
+		{name: "ipv6Adress", want: []word{{"ipv", 0}, {"6", 3}, {"Adress", 4}}},
+		{name: "HTTP2Server", want: []word{{"HTTP", 0}, {"2", 4}, {"Server", 5}}},
+		{name: "Recieve2", want: []word{{"Recieve", 0}, {"2", 7}}},
+		{name: "x509cert", want: []word{{"x", 0}, {"509", 1}, {"cert", 4}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			acronyms, err := acronymList(tt.acronyms)
+			if err != nil {
+				t.Fatalf("acronymList %v", err)
+			}
+			if got := splitIdentifier(tt.name, acronyms); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("splitIdentifier(%q)\ngot %v\nexp %v\n", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_acronymList(t *testing.T) {
+	acronyms, err := acronymList([]string{"gRPC", " IPv6 "})
+	if err != nil {
+		t.Fatalf("acronymList %v", err)
+	}
+	if len(acronyms) != len(Initialisms)+2 || acronyms[0] != "ASCII" {
+		t.Fatalf("expected the initialisms and extra acronyms, longest first, got %v", acronyms)
+	}
+
+	for _, invalid := range []string{"", "I/O", "C++"} {
+		if _, err := acronymList([]string{invalid}); err == nil {
+			t.Errorf("expected error for acronym %q", invalid)
+		}
+	}
+}
+
+func Test_joinCorrection(t *testing.T) {
+	tests := []struct {
+		name, correction, want string
+	}{
+		{name: "alltimeHigh", correction: "all-time", want: "allTime"},
+		{name: "alltime_high", correction: "all-time", want: "all_time"},
+		{name: "MAX_ALLTIME", correction: "ALL-TIME", want: "ALL_TIME"},
+		{name: "_alltime", correction: "all-time", want: "allTime"},
+		{name: "ALLTIME", correction: "ALL-TIME", want: "ALL_TIME"},
+	}
+	for _, tt := range tests {
+		if got := joinCorrection(tt.name, tt.correction); got != tt.want {
+			t.Errorf("joinCorrection(%q, %q) = %q, exp %q", tt.name, tt.correction, got, tt.want)
+		}
+	}
+}
+
+func Test_correct(t *testing.T) {
+	c := newChecker(Flags{})
+	tests := map[string]string{
+		"recieveData":          "receiveData",
+		"max_recieve_attempts": "max_receive_attempts",
+		"_retreive__inital_":   "_retrieve__initial_",
+		"DEFAULT_INITAL_VALUE": "DEFAULT_INITIAL_VALUE",
+		"alltime_high":         "all_time_high",
+	}
+	for name, want := range tests {
+		if got, err := c.correct(name); err != nil || got != want {
+			t.Errorf("correct(%q) = %q, %v, exp %q", name, got, err, want)
+		}
+	}
+}
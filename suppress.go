@@ -0,0 +1,112 @@
+package identypo
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+// KindUnusedSuppression is the Kind of findings reporting suppression comments that did not suppress anything.
+// For these findings, Word holds the text of the comment.
+const KindUnusedSuppression = "unused-suppression"
+
+// suppression is an //identypo:ignore or //nolint:identypo comment, along with the range of code it applies to.
+type suppression struct {
+	pos        token.Pos
+	text       string
+	start, end token.Pos
+	used       bool
+}
+
+// isSuppression reports whether the comment text is a directive suppressing identypo. Directives may be followed
+// by an explanation, for example "//nolint:identypo // wire format".
+func isSuppression(text string) bool {
+	if !strings.HasPrefix(text, "//") {
+		return false
+	}
+
+	directive := strings.TrimPrefix(text, "//")
+	if i := strings.IndexAny(directive, " \t"); i >= 0 {
+		directive = directive[:i]
+	}
+
+	if directive == "identypo:ignore" {
+		return true
+	}
+
+	if strings.HasPrefix(directive, "nolint:") {
+		for _, linter := range strings.Split(strings.TrimPrefix(directive, "nolint:"), ",") {
+			if linter == "identypo" {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// suppressions returns the suppression comments in f. A comment above the package clause applies to the whole file,
+// a comment in the doc of a declaration (function, type, variable, constant, field, etc.) applies to the whole
+// declaration, and any other comment applies to the line it's on.
+func suppressions(fset *token.FileSet, f *ast.File) []*suppression {
+	tf := fset.File(f.Pos())
+	if tf == nil {
+		return nil
+	}
+
+	// map doc comments to the declarations they document
+	docs := make(map[*ast.CommentGroup]ast.Node)
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			docs[n.Doc] = n
+		case *ast.GenDecl:
+			docs[n.Doc] = n
+		case *ast.TypeSpec:
+			docs[n.Doc] = n
+		case *ast.ValueSpec:
+			docs[n.Doc] = n
+		case *ast.ImportSpec:
+			docs[n.Doc] = n
+		case *ast.Field:
+			docs[n.Doc] = n
+		}
+		return true
+	})
+
+	var sups []*suppression
+	for _, group := range f.Comments {
+		for _, comment := range group.List {
+			if !isSuppression(comment.Text) {
+				continue
+			}
+
+			s := &suppression{pos: comment.Pos(), text: comment.Text}
+			if node, ok := docs[group]; ok {
+				s.start, s.end = node.Pos(), node.End()
+			} else if comment.Pos() < f.Package {
+				s.start, s.end = token.Pos(tf.Base()), token.Pos(tf.Base()+tf.Size())
+			} else {
+				line := tf.Line(comment.Pos())
+				s.start = tf.LineStart(line)
+				s.end = token.Pos(tf.Base() + tf.Size())
+				if line < tf.LineCount() {
+					s.end = tf.LineStart(line+1) - 1
+				}
+			}
+			sups = append(sups, s)
+		}
+	}
+
+	return sups
+}
+
+// suppressedBy returns the suppression applying to pos, or nil if there is none.
+func suppressedBy(sups []*suppression, pos token.Pos) *suppression {
+	for _, s := range sups {
+		if s.start <= pos && pos <= s.end {
+			return s
+		}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package identypo
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+)
+
+func Test_findTyposSuppressions(t *testing.T) {
+	tests := []struct {
+		name  string
+		src   string
+		flags Flags
+		want  []string
+	}{
+		{name: "line suppression",
+			src: `package main
+				var begining = true //identypo:ignore
+				var inital = true
+				`,
+			want: []string{
+				"file.go:3:9 \"inital\" should be initial in inital",
+			},
+		},
+		{name: "declaration suppression covers the declaration and its uses",
+			src: `package main
+				// Propogate is part of a public API.
+				//nolint:identypo // can't be changed
+				func Propogate() {
+					inital := 1
+					_ = inital
+				}
+				func main() {
+					Propogate()
+					begining := 0
+					_ = begining
+				}
+				`,
+			want: []string{
+				"file.go:10:6 \"begining\" should be beginning in begining",
+				"file.go:11:10 \"begining\" should be beginning in begining",
+			},
+		},
+		{name: "field suppression",
+			src: `package main
+				type Request struct {
+					//identypo:ignore wire format
+					Referer string
+					Adress  string
+				}
+				`,
+			want: []string{
+				"file.go:5:6 \"Adress\" should be Address in Adress",
+			},
+		},
+		{name: "file suppression",
+			src: `//identypo:ignore
+				package main
+				var begining = true
+				`,
+			want: nil,
+		},
+		{name: "nolint with several linters",
+			src: `package main
+				var begining = true //nolint:gocritic,identypo
+				var inital = true //nolint:gocritic
+				`,
+			want: []string{
+				"file.go:3:9 \"inital\" should be initial in inital",
+			},
+		},
+		{name: "unused suppressions",
+			src: `package main
+				var beginning = true //identypo:ignore
+				var begining = true //nolint:identypo
+				`,
+			flags: Flags{ReportUnusedSuppressions: true},
+			want: []string{
+				"file.go:2:26 unused suppression //identypo:ignore",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fset := token.NewFileSet()
+			f, err := parser.ParseFile(fset, "file.go", tt.src, parser.ParseComments)
+			if err != nil {
+				t.Fatalf("Did not expect error parsing file, %v", err)
+			}
+
+			got := findTypos(fset, []*ast.File{f}, nil, tt.flags)
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("\ngot %v\nexp %v\n", got, tt.want)
+			}
+			for i := range tt.want {
+				if got[i].String() != tt.want[i] {
+					t.Errorf("\ngot %v\nexp %v\n", got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,15 @@
+package fix
+
+// misspelled function referenced from other files
+func Propogate(inital int) int {
+	return inital + 1
+}
+
+// misspelled type with a misspelled field and method
+type Reciever struct {
+	Adress string
+}
+
+func (r *Reciever) Recieve() string {
+	return r.Adress
+}
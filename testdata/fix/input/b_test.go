@@ -0,0 +1,9 @@
+package fix
+
+import "testing"
+
+func TestPropogate(t *testing.T) {
+	if Propogate(1) != 2 {
+		t.Fatal("unexpected result")
+	}
+}
@@ -0,0 +1,8 @@
+package fix
+
+// an interface method is renamed along with the methods implementing it
+type Source interface {
+	Recieve() string
+}
+
+var _ Source = &Reciever{}
@@ -0,0 +1,15 @@
+package fix
+
+// misspelled function referenced from other files
+func Propagate(initial int) int {
+	return initial + 1
+}
+
+// misspelled type with a misspelled field and method
+type Receiver struct {
+	Address string
+}
+
+func (r *Receiver) Receive() string {
+	return r.Address
+}
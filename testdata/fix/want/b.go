@@ -0,0 +1,11 @@
+package fix
+
+func use() int {
+	r := &Receiver{Address: "somewhere"}
+	_ = r.Receive()
+	return Propagate(1)
+}
+
+// begining can't be renamed since beginning already exists
+var begining = 0
+var beginning = 1
@@ -0,0 +1,9 @@
+package fix
+
+import "testing"
+
+func TestPropagate(t *testing.T) {
+	if Propagate(1) != 2 {
+		t.Fatal("unexpected result")
+	}
+}
@@ -0,0 +1,10 @@
+package fix
+
+// Location is part of a wire format, so its misspelled field is kept
+type Location struct {
+	Adress string //identypo:ignore
+}
+
+func (l Location) String() string {
+	return l.Adress
+}
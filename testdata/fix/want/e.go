@@ -0,0 +1,15 @@
+package fix
+
+// an inline interface method is renamed along with the methods implementing it
+type Sequence struct{}
+
+func (Sequence) Beginning() int {
+	return 0
+}
+
+func start(v interface{}) int {
+	if s, ok := v.(interface{ Beginning() int }); ok {
+		return s.Beginning()
+	}
+	return 0
+}
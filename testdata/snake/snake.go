@@ -0,0 +1,13 @@
+package snake
+
+// misspelled snake_case variable
+var max_recieve_attempts = 3
+
+// misspelled SCREAMING_SNAKE_CASE constant
+const DEFAULT_INITAL_TIMEOUT = 10
+
+// misspelled words at the start and end of an identifier
+var _retreive, ALLTIME_ = 0, 0
+
+// correctly spelled, mixed with camelCase
+const HTTP_statusOk = 200
@@ -0,0 +1,32 @@
+package a
+
+import "fmt"
+
+// misspelled function
+func begining() {} // want `"begining" should be beginning in begining`
+
+// misspelled type declaration (its use as a receiver is reported as a reference)
+type succesful int // want `"succesful" should be successful in succesful`
+
+// misspelled function with receiver
+func (s *succesful) begining() {} // want `"begining" should be beginning in begining`
+
+// misspelled constant
+const constantSuccesful = 0 // want `"Succesful" should be Successful in constantSuccesful`
+
+// misspelled label (reported once, at its declaration)
+func main() {
+authorithyLoop: // want `"authorithy" should be authority in authorithyLoop`
+	for i := 0; i < 5; i++ {
+		fmt.Println("loooooooool")
+		continue authorithyLoop
+	}
+}
+
+var varSuccesful = 0 // want `"Succesful" should be Successful in varSuccesful`
+
+// misspelled exported function, marked as part of the public API
+func Recieve() {} // want `"Recieve" should be Receive in Recieve \[public API\]`
+
+// correctly spelled identifiers are not reported
+func successful() {}
@@ -0,0 +1,6 @@
+package a
+
+import "testing"
+
+// misspelled test
+func TestSuccesful(t *testing.T) {} // want `"Succesful" should be Successful in TestSuccesful`
@@ -0,0 +1,6 @@
+package b
+
+import "testing"
+
+// test files are skipped without tests
+func TestSuccesful(t *testing.T) {}
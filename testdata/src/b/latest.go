@@ -0,0 +1,4 @@
+package b
+
+// a file whose name ends in "test.go" isn't a test file, so it's checked even without tests
+func begining() {} // want `"begining" should be beginning in begining`
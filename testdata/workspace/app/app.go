@@ -0,0 +1,8 @@
+package app
+
+import "example.com/dep"
+
+// misspelled function calling a misspelled function from a replaced module
+func Propogate() {
+	dep.Recieve()
+}
@@ -0,0 +1,4 @@
+package dep
+
+// misspelled function
+func Recieve() {}
@@ -0,0 +1,4 @@
+package lib
+
+// misspelled variable
+var inital = 0
@@ -0,0 +1,67 @@
+package identypo
+
+import (
+	"strings"
+	"unicode"
+)
+
+// programmingWords are common words in identifiers, including abbreviations (such as ctx, msg and ptr) and short words
+// (such as of and to), that are known alongside the corrections in misspell's dictionary when splitting run together
+// words such as recieveddata into data and the misspelled recieved, and alongside the words of Flags.WordLists when
+// looking for typos near known words. Jargon that no English word list has, such as goroutine or unmarshal, is
+// included so it isn't mistaken for a typo.
+var programmingWords = strings.Fields(`
+about above access account action active add addr address admin aead after age agent alias all alloc allow alpha alt
+amount an and any api app append archive area arg args array as ask asn assert asset async at atoi attr attribute
+audit auth author auto avg back backend backoff bad balance bar base batch baz be before begin bin bind bit bits black
+blank blob block blue board body bool boot bottom box branch browser bucket buf buffer bufio bug build builtin bundle
+button by byte bytes cache calc call callback can cancel cap card cart case category cell center cgo chan change
+channel char chart chat check checksum child chunk cidr cipher ciphertext class clean clear cli client clone close
+closer cluster cmd cmp code codec col collection color column command comment commit common compare complete component
+compute concat cond condition config conn connect connection const consumer container content context control copy
+core cost count counter cpu create created credit cron cross crypto css ctx cur current cursor custom daily dashboard
+data database date day db deadline debug dec decimal decl decode decoder decrypt dedup deep default defer delay delete
+delim delta deploy depth desc description deserialize dest detail dev device dialer diff digest dim dir direction
+dirent dirs disable disk display dist do doc domain done double down draft drive driver dst due dump dup duration
+dynamic edge elem else email emit empty enable encode encoder end engine entity entry enum env equal err errno error
+errorf errs escape escaper eval event exec exist exit expire export expr ext extra factory fail fallthrough false
+feature feed fetch field fifo file filename filepath files fill filter final find first fixed flag flags flat float
+flow flush folder font foo footer for force fork form format fprintf fprintln frame free from front frontend fsync
+full func function fuzz gateway gen generic get getenv getter getwd global go gob gofmt golang goroutine graph grid
+group guard gzip handle handler hash head header heap height help hex hidden hide high history hit home hook host
+hostname hour html http https icon id ident idx if iface ignore image impl import in inc index inet info init inner
+input insert inst instance int interface internal interval invalid ioctl ioutil is issue it item items iter iterator
+job join json keepalive kernel key keys keyword kind label lang large last latest layer layout lazy leader left legacy
+len length level lexer lib limit line link list listener load local localhost location lock log logger login long
+lookup loop low lower lstat main make manager manifest manual map mapping mark master match matrix max media mem
+member memory menu merge message meta method metric mid middle middleware migration min minute mirror missing mkdir
+mock modal mode model module monitor month mount move msg mtime mtu multi multicast mut mutex mux name namespace
+native net network new next nil no node nonce none normal note notify nsec null num number oauth obj object of off
+offset ok old on op open opt option opts or order origin out outer output override owner pack package padding page
+pair panel panic param params parent parse parser part partition pass password patch path payload peer pem pending
+percent period permission phase phone pick pid ping pipe pixel pkg pkix plain plan platform plugin point pointer
+policy poll pool port pos position post pre prefix prev price primary print printf println priority private proc
+process processor prod producer profile program progress project prompt property proto protobuf protocol provider
+proxy ptr pub public publish push put query queue quit quota random range rate raw read reader ready reason rec
+receive received record recv redirect reduce ref reg regex regexp registry relation release remote remove render reply
+repo report repository req request res reset resolve resource resp response rest result ret retries retry return rev
+review right rlimit role rollback root round route router row rule run rune runner runtime safe sample save scale scan
+schedule schema scope score screen script search sec second secret section security seed seek segment select selector
+self send sender seq sequence serial serializer serve server service session set setter setting settings shadow shape
+share shell shift short signal signature simple single sink size skip slice slot small snapshot so socket sort source
+space span spec split spread sprint sprintf sprintln sqrt square src stack stage standard start stat state static
+status std stderr stdin stdout step stmt stop storage store str strconv stream strict string stringer struct style sub
+subexp subject submatch success suffix sum summary support swap switch symbol symlink sync syntax sys syscall sysctl
+system tab table tag tail target task temp template tenant term test text then thread ticket tier time timeout timer
+timestamp title tmp to today toggle token tokenizer tool top total trace track traffic transaction transform transport
+tree trigger trim true try tuple txn type types uint uintptr unescape unique unit unix unknown unlock unmarshal
+unmarshaler unquote untyped unwrap up update upload upper uri url us usage use user username util utils uuid val valid
+validate validator value values var variable varint vec vendor verify version video view visible volume wait walk warn
+watch we webhook weight whitespace width wildcard window word work worker workflow wrap write writer xml yaml year
+yield zero zlib zone
+`)
+
+// correctionWords returns the words of the correction of a misspell rule, which may be several words, such as all-time.
+func correctionWords(correction string) []string {
+	return strings.FieldsFunc(correction, func(r rune) bool { return !unicode.IsLetter(r) })
+}